@@ -3,9 +3,17 @@ package main
 import (
 	"api/internal/applemusic"
 	"api/internal/configuration"
+	"api/internal/deezer"
+	"api/internal/jellyfin"
 	"api/internal/ping"
+	"api/internal/plex"
+	"api/internal/provider"
+	"api/internal/sandbox"
 	"api/internal/spotify"
+	"api/internal/subsonic"
 	"api/internal/syncer"
+	"api/internal/tidal"
+	"api/internal/youtube"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -21,6 +29,26 @@ func main() {
 		_ = logger.Sync()
 	}(logger)
 
+	provider.RegisterSource(provider.Spotify, spotify.Provider{})
+	provider.RegisterDestination(provider.Spotify, spotify.Provider{})
+	provider.RegisterSource(provider.AppleMusic, applemusic.Provider{})
+	provider.RegisterDestination(provider.AppleMusic, applemusic.Provider{})
+	provider.RegisterDestination(provider.Subsonic, subsonic.Provider{})
+	provider.RegisterDestination(provider.Jellyfin, jellyfin.Provider{})
+	provider.RegisterDestination(provider.Plex, plex.Provider{})
+	provider.RegisterDestination(provider.Deezer, deezer.Provider{})
+	provider.RegisterDestination(provider.Tidal, tidal.Provider{})
+	provider.RegisterDestination(provider.YouTube, youtube.Provider{})
+
+	if sandbox.IsEnabled() {
+		err := sandbox.Start()
+		if err != nil {
+			logger.Error(err.Error())
+			return
+		}
+		sugar.Info("running in sandbox mode")
+	}
+
 	gin.DefaultWriter = GinStartupLoggingWriter(sugar)
 	router := gin.New()
 	err := router.SetTrustedProxies([]string{})
@@ -41,16 +69,35 @@ func main() {
 	router.POST("/apple-music/save-auth", applemusic.SaveAuthEndpoint)
 	router.GET("/apple-music/playlist/synced", applemusic.GetSpotifyPlaylistsEndpoint)
 	router.GET("/apple-music/tracks/spotify-track/:trackId", applemusic.FindTrackEndpoint)
+	router.GET("/apple-music/library/added", applemusic.GetLibrarySongsEndpoint)
+	router.GET("/apple-music/playlists", applemusic.GetLibraryPlaylistsEndpoint)
+
+	router.GET("/deezer/auth", deezer.GetAuthURLEndpoint)
+	router.GET("/deezer/save-auth", deezer.SaveAuthEndpoint)
+
+	router.POST("/tidal/auth", tidal.StartAuthEndpoint)
+
+	router.GET("/youtube/auth", youtube.GetAuthURLEndpoint)
+	router.GET("/youtube/save-auth", youtube.SaveAuthEndpoint)
 
 	router.GET("/spotify/auth", spotify.GetAuthURLEndpoint)
 	router.GET("/spotify/save-auth", spotify.SaveAuthEndpoint)
 	router.GET("/spotify/me", spotify.GetMeEndpoint)
 	router.GET("/spotify/me/playlists", spotify.GetPlaylistsEndpoint)
 	router.GET("/spotify/me/playlists/:playlistId/tracks", spotify.GetPlaylistTracksEndpoint)
+	router.POST("/spotify/playlists", spotify.AddPlaylistEndpoint)
+	router.DELETE("/spotify/playlists/:playlistId", spotify.RemovePlaylistEndpoint)
 
 	router.GET("/sync/status", syncer.StatusSocket)
+	router.GET("/sync/playlists", syncer.GetPlaylistIdsToSyncEndpoint)
 	router.POST("/sync/playlist/:playlistId", syncer.SyncPlaylistEndpoint)
 	router.POST("/sync/all", syncer.SyncPlaylistsEndpoint)
+	router.POST("/sync/albums", syncer.SyncSavedAlbumsEndpoint)
+	router.POST("/sync/target/:targetId", syncer.SyncTargetEndpoint)
+	router.POST("/sync/apple-music/:playlistId", syncer.SyncAppleMusicPlaylistEndpoint)
+	router.POST("/sync/local/:playlistId", syncer.SyncLocalPlaylistEndpoint)
+	router.GET("/sync/providers", syncer.GetProvidersEndpoint)
+	router.POST("/sync/pair/:pairId", syncer.SyncPairEndpoint)
 
 	err = router.Run()
 	if err != nil {
@@ -65,7 +112,7 @@ func CORSMiddleware() gin.HandlerFunc {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Credentials", "true")
 		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST,HEAD,PATCH, OPTIONS, GET, PUT")
+		c.Header("Access-Control-Allow-Methods", "POST,HEAD,PATCH, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
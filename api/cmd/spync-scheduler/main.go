@@ -0,0 +1,82 @@
+package main
+
+import (
+	"api/internal/configuration"
+	"api/internal/sandbox"
+	"github.com/go-co-op/gocron"
+	"go.uber.org/zap"
+	"net/http"
+	"os"
+	"time"
+)
+
+const defaultSyncInterval = 60
+const defaultApiUrl = "http://localhost:8080"
+
+// The scheduler triggers the syncs through the API, so clients connected to the status socket see them as well
+func main() {
+	logger, _ := zap.NewDevelopment()
+	sugar := logger.Sugar()
+	defer func(logger *zap.Logger) {
+		_ = logger.Sync()
+	}(logger)
+
+	// The API seeds the sandbox configuration, the scheduler only has to read the sync interval from it
+	if sandbox.IsEnabled() {
+		err := sandbox.UseDirectory()
+		if err != nil {
+			sugar.Error(err.Error())
+			return
+		}
+		sugar.Info("running in sandbox mode")
+	}
+
+	apiUrl := os.Getenv("SPYNC_API_URL")
+	if apiUrl == "" {
+		apiUrl = defaultApiUrl
+	}
+
+	// The interval is read on every tick, so changes made through the settings apply without a restart
+	var lastSync time.Time
+	syncInterval := 0
+	scheduler := gocron.NewScheduler(time.UTC)
+	scheduler.SingletonModeAll()
+	_, err := scheduler.Every(1).Minute().Do(func() {
+		interval := getSyncInterval(sugar)
+		if interval != syncInterval {
+			sugar.Info("syncing every ", interval, " minutes")
+			syncInterval = interval
+		}
+		if time.Since(lastSync) < time.Duration(interval)*time.Minute {
+			return
+		}
+		lastSync = time.Now()
+
+		sugar.Debug("starting scheduled sync")
+		resp, postErr := http.Post(apiUrl+"/sync/all", "application/json", nil)
+		if postErr != nil {
+			sugar.Error("scheduled sync failed: " + postErr.Error())
+			return
+		}
+		_ = resp.Body.Close()
+		sugar.Debug("scheduled sync done. status: ", resp.StatusCode)
+	})
+	if err != nil {
+		sugar.Error(err.Error())
+		return
+	}
+
+	scheduler.StartBlocking()
+}
+
+func getSyncInterval(sugar *zap.SugaredLogger) int {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		sugar.Error("error reading configuration: " + err.Error())
+		return defaultSyncInterval
+	}
+	if config.SyncInterval <= 0 {
+		return defaultSyncInterval
+	}
+	return config.SyncInterval
+}
@@ -0,0 +1,117 @@
+package applemusic
+
+import (
+	"api/internal/provider"
+	"context"
+	applemusiclib "github.com/minchao/go-apple-music"
+	"net/url"
+	"strings"
+)
+
+type AlbumMatchResult struct {
+	Album *applemusiclib.Album
+	Err   error
+}
+
+// FindAlbums searches the catalog for every album, the results are in the same order as the albums
+func FindAlbums(albums []provider.Album) ([]AlbumMatchResult, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]AlbumMatchResult, len(albums))
+	err = searchInParallel(len(albums), func(index int) {
+		album, findErr := findAlbum(client, albums[index])
+		results[index] = AlbumMatchResult{Album: album, Err: findErr}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func findAlbum(client *applemusiclib.Client, album provider.Album) (*applemusiclib.Album, error) {
+	logger := getLogger()
+
+	if album.UPC != "" {
+		albums, err := getAlbumsByUpc(client, album.UPC)
+		if err != nil {
+			return nil, err
+		}
+		if len(albums.Data) > 0 {
+			logger.Debug("UPC match")
+			return &albums.Data[0], nil
+		}
+	}
+
+	var search *applemusiclib.Search
+	err := withRetry(func() (*applemusiclib.Response, error) {
+		var resp *applemusiclib.Response
+		var searchErr error
+		search, resp, searchErr = client.Catalog.Search(context.TODO(), storefront, &applemusiclib.SearchOptions{
+			Offset: 0,
+			Limit:  25,
+			Types:  "albums",
+			Term:   album.ArtistNames() + " - " + album.Name,
+		})
+		return resp, searchErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if search.Results.Albums == nil {
+		return nil, nil
+	}
+
+	// Unlike tracks, an album is only accepted when both the title and the artist match
+	artistsString := strings.ToLower(album.ArtistNames())
+	for _, candidate := range search.Results.Albums.Data {
+		if strings.EqualFold(candidate.Attributes.Name, album.Name) &&
+			strings.Contains(artistsString, strings.ToLower(candidate.Attributes.ArtistName)) {
+			logger.Debug("artist and title match")
+			return &candidate, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func getAlbumsByUpc(client *applemusiclib.Client, upc string) (*applemusiclib.Albums, error) {
+	query := url.Values{}
+	query.Set("filter[upc]", upc)
+	req, err := client.NewRequest("GET", "v1/catalog/"+storefront+"/albums?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	albums := &applemusiclib.Albums{}
+	err = withRetry(func() (*applemusiclib.Response, error) {
+		return client.Do(context.TODO(), req, albums)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return albums, nil
+}
+
+func AddAlbumsToLibrary(albumIds []string) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("ids[albums]", strings.Join(albumIds, ","))
+	req, err := client.NewRequest("POST", "v1/me/library?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	return withRetry(func() (*applemusiclib.Response, error) {
+		return client.Do(context.TODO(), req, nil)
+	})
+}
@@ -2,16 +2,36 @@ package applemusic
 
 import (
 	"api/internal/configuration"
+	"api/internal/provider"
 	"api/internal/spotify"
 	"api/internal/state"
 	"context"
+	"errors"
 	applemusiclib "github.com/minchao/go-apple-music"
 	spotifylib "github.com/zmb3/spotify/v2"
 	"go.uber.org/zap"
+	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
 )
 
+const storefront = "NL"
+
+const maxIdsPerRequest = 300
+
+var baseURL *url.URL
+
+// SetBaseURL sends every request to another API, which is used by the sandbox
+func SetBaseURL(rawURL string) error {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	baseURL = parsedURL
+	return nil
+}
+
 func SaveAuth(token string) error {
 	stateObj, err := state.GetState()
 	if err != nil {
@@ -27,6 +47,10 @@ func SaveAuth(token string) error {
 }
 
 func CreatePlaylist(name string, spotifyId string) (*applemusiclib.LibraryPlaylist, error) {
+	return CreateNamedPlaylist("Spotify - "+name, name+". Synced with spync. (ID: "+spotifyId+")")
+}
+
+func CreateNamedPlaylist(name string, description string) (*applemusiclib.LibraryPlaylist, error) {
 	client, err := getClient()
 	if err != nil {
 		return nil, err
@@ -34,8 +58,8 @@ func CreatePlaylist(name string, spotifyId string) (*applemusiclib.LibraryPlayli
 
 	playlist, _, err := client.Me.CreateLibraryPlaylist(context.TODO(), applemusiclib.CreateLibraryPlaylist{
 		Attributes: applemusiclib.CreateLibraryPlaylistAttributes{
-			Name:        "Spotify - " + name,
-			Description: name + ". Synced with spync. (ID: " + spotifyId + ")",
+			Name:        name,
+			Description: description,
 		},
 	}, nil)
 
@@ -46,6 +70,24 @@ func CreatePlaylist(name string, spotifyId string) (*applemusiclib.LibraryPlayli
 	return &playlist.Data[0], nil
 }
 
+// DeletePlaylist deletes a library playlist. This is not part of the documented Apple Music API, so it may be
+// refused.
+func DeletePlaylist(playlistId string) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	req, err := client.NewRequest("DELETE", "v1/me/library/playlists/"+playlistId, nil)
+	if err != nil {
+		return err
+	}
+
+	return withRetry(func() (*applemusiclib.Response, error) {
+		return client.Do(context.TODO(), req, nil)
+	})
+}
+
 func AddTracksToPlaylist(playlistId string, trackIds []string) error {
 	client, err := getClient()
 	if err != nil {
@@ -57,7 +99,9 @@ func AddTracksToPlaylist(playlistId string, trackIds []string) error {
 		tracks = append(tracks, applemusiclib.CreateLibraryPlaylistTrack{Id: trackId, Type: "songs"})
 	}
 
-	_, err = client.Me.AddLibraryTracksToPlaylist(context.TODO(), playlistId, applemusiclib.CreateLibraryPlaylistTrackData{Data: tracks})
+	err = withRetry(func() (*applemusiclib.Response, error) {
+		return client.Me.AddLibraryTracksToPlaylist(context.TODO(), playlistId, applemusiclib.CreateLibraryPlaylistTrackData{Data: tracks})
+	})
 	if err != nil {
 		return err
 	}
@@ -65,22 +109,157 @@ func AddTracksToPlaylist(playlistId string, trackIds []string) error {
 	return nil
 }
 
-func FindTrack(item *spotifylib.FullTrack) (*applemusiclib.Song, error) {
-	logger := getLogger()
+func GetPlaylistTrackIds(playlistId string) ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	tracks, err := getPlaylistTracks(client, playlistId)
+	if err != nil {
+		return nil, err
+	}
 
+	// Library tracks have their own ids, the catalog id is what gets added to playlists
+	trackIds := make([]string, 0)
+	for _, track := range tracks {
+		if track.Attributes.PlayParams != nil && track.Attributes.PlayParams.CatalogId != "" {
+			trackIds = append(trackIds, track.Attributes.PlayParams.CatalogId)
+		}
+	}
+
+	return trackIds, nil
+}
+
+// RemoveTracksFromPlaylist removes every occurrence of the catalog songs from a library playlist. This is not part
+// of the documented Apple Music API, so it may be refused.
+func RemoveTracksFromPlaylist(playlistId string, trackIds []string) error {
 	client, err := getClient()
 	if err != nil {
+		return err
+	}
+
+	tracks, err := getPlaylistTracks(client, playlistId)
+	if err != nil {
+		return err
+	}
+
+	remove := make(map[string]bool)
+	for _, trackId := range trackIds {
+		remove[trackId] = true
+	}
+
+	libraryTrackIds := make([]string, 0)
+	for _, track := range tracks {
+		if track.Attributes.PlayParams != nil && remove[track.Attributes.PlayParams.CatalogId] {
+			libraryTrackIds = append(libraryTrackIds, track.Id)
+		}
+	}
+	if len(libraryTrackIds) == 0 {
+		return nil
+	}
+
+	query := url.Values{}
+	query.Set("ids[library-songs]", strings.Join(libraryTrackIds, ","))
+	query.Set("mode", "all")
+	req, err := client.NewRequest("DELETE", "v1/me/library/playlists/"+playlistId+"/tracks?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	return withRetry(func() (*applemusiclib.Response, error) {
+		return client.Do(context.TODO(), req, nil)
+	})
+}
+
+func getPlaylistTracks(client *applemusiclib.Client, playlistId string) ([]applemusiclib.Song, error) {
+	tracks, err := client.Me.GetLibraryPlaylistTracks(context.TODO(), playlistId, nil)
+	if err != nil {
+		// Apple Music responds with a 404 when a playlist has no tracks
+		var errorResponse *applemusiclib.ErrorResponse
+		if errors.As(err, &errorResponse) && errorResponse.Response.StatusCode == http.StatusNotFound {
+			return make([]applemusiclib.Song, 0), nil
+		}
 		return nil, err
 	}
 
+	return tracks, nil
+}
+
+// GetSongs returns the catalog songs with the ids
+func GetSongs(trackIds []string) ([]applemusiclib.Song, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	songs := make([]applemusiclib.Song, 0)
+	for start := 0; start < len(trackIds); start += maxIdsPerRequest {
+		end := start + maxIdsPerRequest
+		if end > len(trackIds) {
+			end = len(trackIds)
+		}
+
+		var page *applemusiclib.Songs
+		err = withRetry(func() (*applemusiclib.Response, error) {
+			var resp *applemusiclib.Response
+			var getErr error
+			page, resp, getErr = client.Catalog.GetSongsByIds(context.TODO(), storefront, trackIds[start:end], nil)
+			return resp, getErr
+		})
+		if err != nil {
+			return nil, err
+		}
+		songs = append(songs, page.Data...)
+	}
+
+	return songs, nil
+}
+
+func AddTracksToLibrary(trackIds []string) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("ids[songs]", strings.Join(trackIds, ","))
+	req, err := client.NewRequest("POST", "v1/me/library?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	return withRetry(func() (*applemusiclib.Response, error) {
+		return client.Do(context.TODO(), req, nil)
+	})
+}
+
+func FindTrack(item *spotifylib.FullTrack) (*applemusiclib.Song, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return findTrack(client, spotify.ToTrack(item, ""))
+}
+
+func findTrack(client *applemusiclib.Client, item provider.Track) (*applemusiclib.Song, error) {
+	logger := getLogger()
+
 	// Remove "feat." because Apple Music often does not use it in song titles
 	var removeRegex = regexp.MustCompile(`(\((?:feat.|ft.|with) .+\))`)
 
-	search, _, err := client.Catalog.Search(context.TODO(), "NL", &applemusiclib.SearchOptions{
-		Offset: 0,
-		Limit:  25,
-		Types:  "songs",
-		Term:   item.Artists[0].Name + " - " + removeRegex.ReplaceAllString(item.Name, ""),
+	var search *applemusiclib.Search
+	err := withRetry(func() (*applemusiclib.Response, error) {
+		var resp *applemusiclib.Response
+		var searchErr error
+		search, resp, searchErr = client.Catalog.Search(context.TODO(), storefront, &applemusiclib.SearchOptions{
+			Offset: 0,
+			Limit:  25,
+			Types:  "songs",
+			Term:   getSearchTerm(item, removeRegex),
+		})
+		return resp, searchErr
 	})
 	if err != nil {
 		return nil, err
@@ -90,8 +269,10 @@ func FindTrack(item *spotifylib.FullTrack) (*applemusiclib.Song, error) {
 		return nil, nil
 	} else {
 		// Check for ISRC
+		// Local files have no ISRC
+		isrc := item.ISRC
 		for _, track := range search.Results.Songs.Data {
-			if item.ExternalIDs["isrc"] == track.Attributes.ISRC {
+			if isrc != "" && isrc == track.Attributes.ISRC {
 				// If isrc matches, it is the correct match
 				logger.Debug("ISRC match")
 				return &track, nil
@@ -100,7 +281,7 @@ func FindTrack(item *spotifylib.FullTrack) (*applemusiclib.Song, error) {
 
 		// Check if joined artistnames contains the artistname
 		for _, track := range search.Results.Songs.Data {
-			artistsString := spotify.GetArtistNames(item)
+			artistsString := item.ArtistNames()
 			if strings.Contains(artistsString, track.Attributes.ArtistName) {
 				logger.Debug("artistname was a rough match")
 				return &track, nil
@@ -112,7 +293,31 @@ func FindTrack(item *spotifylib.FullTrack) (*applemusiclib.Song, error) {
 	}
 }
 
+func getSearchTerm(item provider.Track, removeRegex *regexp.Regexp) string {
+	name := removeRegex.ReplaceAllString(item.Name, "")
+	if len(item.Artists) == 0 {
+		return name
+	}
+	return item.Artists[0] + " - " + name
+}
+
 func GetSpotifyPlaylists() ([]applemusiclib.LibraryPlaylist, error) {
+	playlists, err := GetLibraryPlaylists()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]applemusiclib.LibraryPlaylist, 0)
+	for _, playlist := range playlists {
+		if strings.HasPrefix(playlist.Attributes.Name, "Spotify - ") {
+			items = append(items, playlist)
+		}
+	}
+
+	return items, nil
+}
+
+func GetLibraryPlaylists() ([]applemusiclib.LibraryPlaylist, error) {
 	client, err := getClient()
 	if err != nil {
 		return nil, err
@@ -129,11 +334,7 @@ func GetSpotifyPlaylists() ([]applemusiclib.LibraryPlaylist, error) {
 			return nil, err
 		}
 
-		for _, playlist := range playlists.Data {
-			if strings.HasPrefix(playlist.Attributes.Name, "Spotify - ") {
-				items = append(items, playlist)
-			}
-		}
+		items = append(items, playlists.Data...)
 
 		if len(playlists.Data) < 100 {
 			hasMore = false
@@ -145,6 +346,28 @@ func GetSpotifyPlaylists() ([]applemusiclib.LibraryPlaylist, error) {
 	return items, nil
 }
 
+func GetLibraryPlaylist(playlistId string) (*applemusiclib.LibraryPlaylist, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	playlists, _, err := client.Me.GetLibraryPlaylist(context.TODO(), playlistId, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(playlists.Data) == 0 {
+		return nil, errors.New("playlist not found: " + playlistId)
+	}
+
+	return &playlists.Data[0], nil
+}
+
+// IsSyncedPlaylist reports whether the playlist was created by spync from a Spotify playlist
+func IsSyncedPlaylist(playlist *applemusiclib.LibraryPlaylist) bool {
+	return strings.HasPrefix(playlist.Attributes.Name, "Spotify - ")
+}
+
 func GetSyncedPlaylist(playlistId string) (*applemusiclib.LibraryPlaylist, error) {
 	playlists, err := GetSpotifyPlaylists()
 	if err != nil {
@@ -153,9 +376,13 @@ func GetSyncedPlaylist(playlistId string) (*applemusiclib.LibraryPlaylist, error
 
 	idRegex := regexp.MustCompile(`\(ID: (.*?)\)`)
 	for _, playlist := range playlists {
+		if playlist.Attributes.Description == nil {
+			continue
+		}
+		// Archive and all-time playlists have a different description and are skipped
 		description := playlist.Attributes.Description.Standard
 		matches := idRegex.FindStringSubmatch(description)
-		if playlistId == matches[1] {
+		if len(matches) > 1 && playlistId == matches[1] {
 			return &playlist, nil
 		}
 	}
@@ -191,6 +418,9 @@ func getClient() (*applemusiclib.Client, error) {
 	}
 	tp := applemusiclib.Transport{Token: config.AppleMusic.DeveloperToken, MusicUserToken: stateObj.AppleMusic.AccessToken}
 	client := applemusiclib.NewClient(tp.Client())
+	if baseURL != nil {
+		client.BaseURL = baseURL
+	}
 
 	return client, nil
 }
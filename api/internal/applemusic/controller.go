@@ -2,6 +2,7 @@ package applemusic
 
 import (
 	"api/internal/spotify"
+	"api/internal/state"
 	"github.com/gin-gonic/gin"
 )
 
@@ -40,6 +41,30 @@ func GetSpotifyPlaylistsEndpoint(c *gin.Context) {
 	}
 }
 
+func GetLibraryPlaylistsEndpoint(c *gin.Context) {
+	playlists, err := GetLibraryPlaylists()
+	if err != nil {
+		c.JSON(400, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, playlists)
+}
+
+func GetLibrarySongsEndpoint(c *gin.Context) {
+	stateObj, err := state.GetState()
+	if err != nil {
+		c.JSON(500, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, stateObj.AppleMusic.LibrarySongIds)
+}
+
 func SaveAuthEndpoint(c *gin.Context) {
 	var tokenObj TokenObject
 	decodeErr := c.BindJSON(&tokenObj)
@@ -0,0 +1,76 @@
+package applemusic
+
+import (
+	"api/internal/configuration"
+	"api/internal/provider"
+	"api/internal/ratelimit"
+	applemusiclib "github.com/minchao/go-apple-music"
+	"sync"
+)
+
+const defaultSearchConcurrency = 4
+const defaultSearchRate = 10
+
+type MatchResult struct {
+	Song *applemusiclib.Song
+	Err  error
+}
+
+// FindTracks searches the catalog for every item using a pool of workers that share a single client. The searches
+// are rate limited and the results are in the same order as the items.
+func FindTracks(items []provider.Track) ([]MatchResult, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]MatchResult, len(items))
+	err = searchInParallel(len(items), func(index int) {
+		song, findErr := findTrack(client, items[index])
+		results[index] = MatchResult{Song: song, Err: findErr}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// searchInParallel calls search for every index from 0 to count, limited by the configured concurrency and rate
+func searchInParallel(count int, search func(index int)) error {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return err
+	}
+
+	concurrency := config.AppleMusic.SearchConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultSearchConcurrency
+	}
+	rate := config.AppleMusic.SearchRate
+	if rate <= 0 {
+		rate = defaultSearchRate
+	}
+	limiter := ratelimit.NewLimiter(rate, concurrency)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				limiter.Wait()
+				search(index)
+			}
+		}()
+	}
+
+	for index := 0; index < count; index++ {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+
+	return nil
+}
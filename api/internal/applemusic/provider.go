@@ -0,0 +1,183 @@
+package applemusic
+
+import (
+	"api/internal/provider"
+	"api/internal/state"
+	"errors"
+	applemusiclib "github.com/minchao/go-apple-music"
+)
+
+// Provider makes the Apple Music library available as a source and as a destination
+type Provider struct{}
+
+// ToTrack converts a catalog song, so it can be compared with tracks of other providers
+func ToTrack(song applemusiclib.Song) provider.Track {
+	return provider.Track{
+		Id:         song.Id,
+		Name:       song.Attributes.Name,
+		Artists:    []string{song.Attributes.ArtistName},
+		Album:      song.Attributes.AlbumName,
+		ISRC:       song.Attributes.ISRC,
+		DurationMs: int(song.Attributes.DurationInMillis),
+		Explicit:   song.Attributes.ContentRating == "explicit",
+	}
+}
+
+func toPlaylist(playlist applemusiclib.LibraryPlaylist) provider.Playlist {
+	description := ""
+	if playlist.Attributes.Description != nil {
+		description = playlist.Attributes.Description.Standard
+	}
+	return provider.Playlist{
+		Id:          playlist.Id,
+		Name:        playlist.Attributes.Name,
+		Description: description,
+	}
+}
+
+func (Provider) GetPlaylists() ([]provider.Playlist, error) {
+	playlists, err := GetLibraryPlaylists()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]provider.Playlist, 0)
+	for _, playlist := range playlists {
+		if !IsSyncedPlaylist(&playlist) {
+			items = append(items, toPlaylist(playlist))
+		}
+	}
+	return items, nil
+}
+
+// GetPlaylist refuses the playlists that are synced from Spotify, they would be synced back again
+func (Provider) GetPlaylist(playlistId string) (*provider.Playlist, error) {
+	playlist, err := GetLibraryPlaylist(playlistId)
+	if err != nil {
+		return nil, err
+	}
+	if IsSyncedPlaylist(playlist) {
+		return nil, errors.New("playlist is synced from Spotify: " + playlist.Attributes.Name)
+	}
+
+	item := toPlaylist(*playlist)
+	return &item, nil
+}
+
+// GetPlaylistTracks returns the catalog songs of a library playlist. Apple Music does not report when songs were
+// added to a playlist, so AddedAt is always zero.
+func (Provider) GetPlaylistTracks(playlistId string) ([]provider.Track, []state.TrackReport, error) {
+	trackIds, err := GetPlaylistTrackIds(playlistId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	songs, err := GetSongs(trackIds)
+	if err != nil {
+		return nil, nil, err
+	}
+	songsById := make(map[string]applemusiclib.Song)
+	for _, song := range songs {
+		songsById[song.Id] = song
+	}
+
+	tracks := make([]provider.Track, 0)
+	skipped := make([]state.TrackReport, 0)
+	for _, trackId := range trackIds {
+		song, ok := songsById[trackId]
+		if !ok {
+			skipped = append(skipped, state.TrackReport{TrackId: trackId, Reason: "not available in the catalog"})
+			continue
+		}
+		tracks = append(tracks, ToTrack(song))
+	}
+	return tracks, skipped, nil
+}
+
+func (Provider) FindTracks(tracks []provider.Track) ([]provider.MatchResult, error) {
+	matchResults, err := FindTracks(tracks)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]provider.MatchResult, 0)
+	for _, matchResult := range matchResults {
+		result := provider.MatchResult{Err: matchResult.Err}
+		if matchResult.Song != nil {
+			result.TrackId = matchResult.Song.Id
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (Provider) GetSyncedPlaylist(sourcePlaylistId string, _ string) (*provider.Playlist, error) {
+	playlist, err := GetSyncedPlaylist(sourcePlaylistId)
+	if err != nil || playlist == nil {
+		return nil, err
+	}
+
+	item := toPlaylist(*playlist)
+	return &item, nil
+}
+
+func (Provider) CreatePlaylist(name string, sourcePlaylistId string) (*provider.Playlist, error) {
+	playlist, err := CreatePlaylist(name, sourcePlaylistId)
+	if err != nil {
+		return nil, err
+	}
+
+	item := toPlaylist(*playlist)
+	return &item, nil
+}
+
+func (Provider) CreateNamedPlaylist(name string, description string) (*provider.Playlist, error) {
+	playlist, err := CreateNamedPlaylist(name, description)
+	if err != nil {
+		return nil, err
+	}
+
+	item := toPlaylist(*playlist)
+	return &item, nil
+}
+
+func (Provider) GetPlaylistTrackIds(playlistId string) ([]string, error) {
+	return GetPlaylistTrackIds(playlistId)
+}
+
+func (Provider) AddTracks(playlistId string, trackIds []string) error {
+	return AddTracksToPlaylist(playlistId, trackIds)
+}
+
+func (Provider) RemoveTracks(playlistId string, trackIds []string) error {
+	return RemoveTracksFromPlaylist(playlistId, trackIds)
+}
+
+func (Provider) DeletePlaylist(playlistId string) error {
+	return DeletePlaylist(playlistId)
+}
+
+func (Provider) AddToLibrary(trackIds []string) error {
+	return AddTracksToLibrary(trackIds)
+}
+
+func (Provider) FindAlbums(albums []provider.Album) ([]provider.MatchResult, error) {
+	matchResults, err := FindAlbums(albums)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]provider.MatchResult, 0)
+	for _, matchResult := range matchResults {
+		result := provider.MatchResult{Err: matchResult.Err}
+		if matchResult.Album != nil {
+			result.TrackId = matchResult.Album.Id
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (Provider) AddAlbums(albumIds []string) error {
+	return AddAlbumsToLibrary(albumIds)
+}
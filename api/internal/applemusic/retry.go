@@ -0,0 +1,58 @@
+package applemusic
+
+import (
+	"errors"
+	applemusiclib "github.com/minchao/go-apple-music"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const maxAttempts = 5
+const initialBackoff = time.Second
+
+// withRetry runs the request until it succeeds, retrying with exponential backoff when Apple Music responds with
+// a 429 or a 5xx status.
+func withRetry(request func() (*applemusiclib.Response, error)) error {
+	logger := getLogger()
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		resp, err := request()
+		if err == nil {
+			return nil
+		}
+		if attempt == maxAttempts || !isRetryable(resp, err) {
+			return err
+		}
+
+		wait := backoff
+		if retryAfter := getRetryAfter(resp); retryAfter > wait {
+			wait = retryAfter
+		}
+		logger.Warn("request failed, retrying in ", wait, " (attempt ", attempt, "/", maxAttempts, "): ", err.Error())
+		time.Sleep(wait)
+		backoff *= 2
+	}
+}
+
+func isRetryable(resp *applemusiclib.Response, err error) bool {
+	var tooManyRequestsErr *applemusiclib.TooManyRequestsError
+	if errors.As(err, &tooManyRequestsErr) {
+		return true
+	}
+
+	return resp != nil && resp.Response != nil && resp.StatusCode >= http.StatusInternalServerError
+}
+
+func getRetryAfter(resp *applemusiclib.Response) time.Duration {
+	if resp == nil || resp.Response == nil {
+		return 0
+	}
+
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
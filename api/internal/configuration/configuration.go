@@ -8,8 +8,30 @@ import (
 	"os"
 )
 
+const (
+	RuleOwned         = "owned"
+	RuleCollaborative = "collaborative"
+	RuleNameGlob      = "name-glob"
+	RuleNameRegex     = "name-regex"
+	RuleOwner         = "owner"
+)
+
+type SelectionRule struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// SelectionConfig selects the playlists of the user that match any include rule and none of the exclude rules
+type SelectionConfig struct {
+	Include []SelectionRule `json:"include"`
+	Exclude []SelectionRule `json:"exclude"`
+}
+
 type SpotifyConfig struct {
-	PlaylistIds []string `json:"playlist-ids"`
+	PlaylistIds     []string        `json:"playlist-ids"`
+	Sources         []string        `json:"sources"`
+	SyncSavedAlbums bool            `json:"sync-saved-albums"`
+	Selection       SelectionConfig `json:"selection"`
 
 	ClientId     string `json:"client-id"`
 	ClientSecret string `json:"client-secret"`
@@ -18,12 +40,182 @@ type SpotifyConfig struct {
 
 type AppleMusicConfig struct {
 	DeveloperToken string `json:"developer-token"`
+
+	SearchConcurrency int     `json:"search-concurrency"`
+	SearchRate        float64 `json:"search-rate"`
+
+	AddToLibrary bool `json:"add-to-library"`
+
+	// PlaylistIds are the Apple Music library playlists that are synced to Spotify
+	PlaylistIds []string `json:"playlist-ids"`
+}
+
+const (
+	TargetPlaylist = "playlist"
+	TargetLibrary  = "library"
+)
+
+const (
+	ModeAppend  = "append"
+	ModeArchive = "archive"
+	ModeReplace = "replace"
+	ModeTwoWay  = "two-way"
+)
+
+// Conflict policies decide what happens with a track that was removed on only one side of a two-way sync
+const (
+	ConflictSourceWins = "source-wins"
+	ConflictUnion      = "union"
+	ConflictLatest     = "latest"
+)
+
+// ArchiveConfig is used in archive mode, where every snapshot of a playlist is copied into a new playlist
+type ArchiveConfig struct {
+	NameTemplate string `json:"name-template"`
+	Retention    int    `json:"retention"`
+	AllTime      bool   `json:"all-time"`
+}
+
+// FilterConfig drops tracks of a playlist before they are searched on Apple Music
+type FilterConfig struct {
+	ExcludeExplicit    bool     `json:"exclude-explicit"`
+	MinDurationSeconds int      `json:"min-duration-seconds"`
+	MaxDurationSeconds int      `json:"max-duration-seconds"`
+	ExcludeArtists     []string `json:"exclude-artists"`
+	MaxTracks          int      `json:"max-tracks"`
+}
+
+type PlaylistConfig struct {
+	Name  string `json:"name,omitempty"`
+	Owner string `json:"owner,omitempty"`
+
+	KeepDuplicates bool   `json:"keep-duplicates"`
+	Target         string `json:"target"`
+	AddToLibrary   *bool  `json:"add-to-library,omitempty"`
+
+	Mode           string        `json:"mode"`
+	ConflictPolicy string        `json:"conflict-policy,omitempty"`
+	Archive        ArchiveConfig `json:"archive"`
+	Filters        FilterConfig  `json:"filters"`
+
+	// Destinations are the providers the playlist is synced to besides Apple Music
+	Destinations []string `json:"destinations,omitempty"`
+}
+
+const (
+	OrderingConcatenated = "concatenated"
+	OrderingInterleaved  = "interleaved"
+	OrderingDateAdded    = "date-added"
+)
+
+// TargetConfig merges several playlists of the source into one playlist of the destination, which are Spotify and
+// Apple Music by default. The ordering applies when the playlist is created, tracks that are added later are
+// appended at the end.
+type TargetConfig struct {
+	Name        string   `json:"name"`
+	Source      string   `json:"source,omitempty"`
+	Destination string   `json:"destination,omitempty"`
+	SourceIds   []string `json:"source-ids"`
+	Ordering    string   `json:"ordering"`
+}
+
+// SubsonicConfig is the server of the Subsonic destination, such as Navidrome
+type SubsonicConfig struct {
+	Url      string `json:"url"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// JellyfinConfig is the server of the Jellyfin destination. Playlists are created for the user.
+type JellyfinConfig struct {
+	Url    string `json:"url"`
+	ApiKey string `json:"api-key"`
+	UserId string `json:"user-id"`
+}
+
+// PlexConfig is the server of the Plex destination. Tracks are searched in the music library with the section id.
+type PlexConfig struct {
+	Url       string `json:"url"`
+	Token     string `json:"token"`
+	SectionId string `json:"section-id"`
+}
+
+// DeezerConfig is the app of the Deezer destination, the redirect url has to be the save-auth endpoint
+type DeezerConfig struct {
+	AppId       string `json:"app-id"`
+	Secret      string `json:"secret"`
+	RedirectUrl string `json:"redirect-url"`
+}
+
+// TidalConfig is the app of the Tidal destination. The catalog is searched in the country of the user.
+type TidalConfig struct {
+	ClientId     string `json:"client-id"`
+	ClientSecret string `json:"client-secret"`
+	CountryCode  string `json:"country-code"`
+}
+
+// YouTubeConfig is the Google app of the YouTube Music destination, the redirect url has to be the save-auth endpoint
+type YouTubeConfig struct {
+	ClientId     string `json:"client-id"`
+	ClientSecret string `json:"client-secret"`
+	RedirectUrl  string `json:"redirect-url"`
+}
+
+const (
+	FormatM3U8 = "m3u8"
+	FormatXSPF = "xspf"
+)
+
+// LocalConfig writes the Spotify playlists as playlist files for offline players. Tracks are matched against the
+// tags of the files in the music directory.
+type LocalConfig struct {
+	MusicDirectory    string   `json:"music-directory"`
+	PlaylistDirectory string   `json:"playlist-directory"`
+	Format            string   `json:"format"`
+	PlaylistIds       []string `json:"playlist-ids"`
+}
+
+// PairConfig syncs a playlist of one provider into a playlist of another provider. A two-way sync needs a source
+// that is also a destination, and the other way around.
+type PairConfig struct {
+	Source         string        `json:"source"`
+	Destination    string        `json:"destination"`
+	PlaylistId     string        `json:"playlist-id"`
+	KeepDuplicates bool          `json:"keep-duplicates"`
+	Mode           string        `json:"mode"`
+	ConflictPolicy string        `json:"conflict-policy,omitempty"`
+	Archive        ArchiveConfig `json:"archive"`
+	Filters        FilterConfig  `json:"filters"`
 }
 
 type Config struct {
-	SyncInterval int              `json:"sync-interval"`
-	AppleMusic   AppleMusicConfig `json:"apple-music"`
-	Spotify      SpotifyConfig    `json:"spotify"`
+	SyncInterval int                       `json:"sync-interval"`
+	AppleMusic   AppleMusicConfig          `json:"apple-music"`
+	Spotify      SpotifyConfig             `json:"spotify"`
+	Playlists    map[string]PlaylistConfig `json:"playlists"`
+	Targets      map[string]TargetConfig   `json:"targets"`
+	Pairs        map[string]PairConfig     `json:"pairs"`
+	Subsonic     SubsonicConfig            `json:"subsonic"`
+	Jellyfin     JellyfinConfig            `json:"jellyfin"`
+	Plex         PlexConfig                `json:"plex"`
+	Local        LocalConfig               `json:"local"`
+	Deezer       DeezerConfig              `json:"deezer"`
+	Tidal        TidalConfig               `json:"tidal"`
+	YouTube      YouTubeConfig             `json:"youtube"`
+}
+
+func (c Config) GetPlaylistConfig(playlistId string) PlaylistConfig {
+	return c.Playlists[playlistId]
+}
+
+// ShouldAddToLibrary reports whether synced songs should also be added to the Apple Music library. The setting of
+// the playlist takes precedence over the global one.
+func (c Config) ShouldAddToLibrary(playlistId string) bool {
+	playlistConfig := c.GetPlaylistConfig(playlistId)
+	if playlistConfig.AddToLibrary != nil {
+		return *playlistConfig.AddToLibrary
+	}
+	return c.AppleMusic.AddToLibrary
 }
 
 const configFilePath = "configuration.json"
@@ -78,6 +270,52 @@ func SaveConfiguration(config Config, replaceProtected bool) error {
 	return nil
 }
 
+// AddPlaylist adds a Spotify playlist to the playlists that are synced and stores its name and owner
+func AddPlaylist(playlistId string, name string, owner string) error {
+	config, err := GetConfiguration()
+	if err != nil {
+		return err
+	}
+
+	exists := false
+	for _, existingId := range config.Spotify.PlaylistIds {
+		if existingId == playlistId {
+			exists = true
+		}
+	}
+	if !exists {
+		config.Spotify.PlaylistIds = append(config.Spotify.PlaylistIds, playlistId)
+	}
+
+	if config.Playlists == nil {
+		config.Playlists = make(map[string]PlaylistConfig)
+	}
+	playlistConfig := config.Playlists[playlistId]
+	playlistConfig.Name = name
+	playlistConfig.Owner = owner
+	config.Playlists[playlistId] = playlistConfig
+
+	return SaveConfiguration(config, false)
+}
+
+func RemovePlaylist(playlistId string) error {
+	config, err := GetConfiguration()
+	if err != nil {
+		return err
+	}
+
+	playlistIds := make([]string, 0)
+	for _, existingId := range config.Spotify.PlaylistIds {
+		if existingId != playlistId {
+			playlistIds = append(playlistIds, existingId)
+		}
+	}
+	config.Spotify.PlaylistIds = playlistIds
+	delete(config.Playlists, playlistId)
+
+	return SaveConfiguration(config, false)
+}
+
 func replaceProtectedProperties(config *Config) error {
 	logger := getLogger()
 	existingConfig, getErr := GetConfiguration()
@@ -88,6 +326,17 @@ func replaceProtectedProperties(config *Config) error {
 
 	config.Spotify.PlaylistIds = existingConfig.Spotify.PlaylistIds
 
+	// The name and owner are stored when a playlist is added, clients do not send them back
+	for playlistId, existingPlaylistConfig := range existingConfig.Playlists {
+		if config.Playlists == nil {
+			config.Playlists = make(map[string]PlaylistConfig)
+		}
+		playlistConfig := config.Playlists[playlistId]
+		playlistConfig.Name = existingPlaylistConfig.Name
+		playlistConfig.Owner = existingPlaylistConfig.Owner
+		config.Playlists[playlistId] = playlistConfig
+	}
+
 	return nil
 }
 
@@ -0,0 +1,302 @@
+package deezer
+
+import (
+	"api/internal/configuration"
+	"api/internal/state"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	apiURL     = "https://api.deezer.com/"
+	connectURL = "https://connect.deezer.com/oauth/"
+)
+
+// permissions allow creating playlists, offline access makes the token never expire
+const permissions = "basic_access,manage_library,offline_access"
+
+type Artist struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type Album struct {
+	Id    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+type Track struct {
+	Id           int      `json:"id"`
+	Title        string   `json:"title"`
+	ISRC         string   `json:"isrc"`
+	Duration     int      `json:"duration"`
+	Explicit     bool     `json:"explicit_lyrics"`
+	Artist       Artist   `json:"artist"`
+	Contributors []Artist `json:"contributors"`
+	Album        Album    `json:"album"`
+}
+
+type Playlist struct {
+	Id          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type apiError struct {
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
+
+// errNotFound is returned by Deezer for unknown ISRCs
+const errNotFound = 800
+
+func GetAuthUrl() string {
+	config, _ := configuration.GetConfiguration()
+	params := url.Values{}
+	params.Set("app_id", config.Deezer.AppId)
+	params.Set("redirect_uri", config.Deezer.RedirectUrl)
+	params.Set("perms", permissions)
+	params.Set("state", fmt.Sprint("spync-", rand.Int()))
+	return connectURL + "auth.php?" + params.Encode()
+}
+
+// SaveAuth exchanges the code of the redirect for an access token
+func SaveAuth(code string) error {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return err
+	}
+	if code == "" {
+		return errors.New("Deezer did not return a code")
+	}
+
+	params := url.Values{}
+	params.Set("app_id", config.Deezer.AppId)
+	params.Set("secret", config.Deezer.Secret)
+	params.Set("code", code)
+	params.Set("output", "json")
+	resp, err := http.Get(connectURL + "access_token.php?" + params.Encode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	token := struct {
+		AccessToken string `json:"access_token"`
+		Expires     int    `json:"expires"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return errors.New("could not get Deezer access token: " + resp.Status)
+	}
+
+	stateObj, err := state.GetState()
+	if err != nil {
+		return err
+	}
+	stateObj.Deezer.AccessToken = token.AccessToken
+	stateObj.Deezer.Expiry = time.Time{}
+	if token.Expires > 0 {
+		stateObj.Deezer.Expiry = time.Now().Add(time.Duration(token.Expires) * time.Second)
+	}
+	return state.SaveState(stateObj)
+}
+
+// GetTrackByISRC returns nil when Deezer does not know the ISRC
+func GetTrackByISRC(isrc string) (*Track, error) {
+	track := &Track{}
+	err := request("GET", "track/isrc:"+url.PathEscape(isrc), url.Values{}, track)
+	var deezerErr *Error
+	if errors.As(err, &deezerErr) && deezerErr.Code == errNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return track, nil
+}
+
+func SearchTracks(artist string, title string) ([]Track, error) {
+	params := url.Values{}
+	params.Set("q", "artist:\""+artist+"\" track:\""+title+"\"")
+	resp := struct {
+		Data []Track `json:"data"`
+	}{}
+	err := request("GET", "search/track", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+func GetPlaylists() ([]Playlist, error) {
+	playlists := make([]Playlist, 0)
+	for index := 0; ; index += 100 {
+		params := url.Values{}
+		params.Set("index", strconv.Itoa(index))
+		params.Set("limit", "100")
+		resp := struct {
+			Data []Playlist `json:"data"`
+			Next string     `json:"next"`
+		}{}
+		err := request("GET", "user/me/playlists", params, &resp)
+		if err != nil {
+			return nil, err
+		}
+		playlists = append(playlists, resp.Data...)
+		if resp.Next == "" {
+			return playlists, nil
+		}
+	}
+}
+
+// GetPlaylist returns the description, which is left out of the list of playlists
+func GetPlaylist(playlistId string) (*Playlist, error) {
+	playlist := &Playlist{}
+	err := request("GET", "playlist/"+playlistId, url.Values{}, playlist)
+	if err != nil {
+		return nil, err
+	}
+	return playlist, nil
+}
+
+func GetPlaylistTrackIds(playlistId string) ([]string, error) {
+	trackIds := make([]string, 0)
+	for index := 0; ; index += 100 {
+		params := url.Values{}
+		params.Set("index", strconv.Itoa(index))
+		params.Set("limit", "100")
+		resp := struct {
+			Data []Track `json:"data"`
+			Next string  `json:"next"`
+		}{}
+		err := request("GET", "playlist/"+playlistId+"/tracks", params, &resp)
+		if err != nil {
+			return nil, err
+		}
+		for _, track := range resp.Data {
+			trackIds = append(trackIds, strconv.Itoa(track.Id))
+		}
+		if resp.Next == "" {
+			return trackIds, nil
+		}
+	}
+}
+
+func CreatePlaylist(name string, description string) (*Playlist, error) {
+	params := url.Values{}
+	params.Set("title", name)
+	resp := struct {
+		Id int `json:"id"`
+	}{}
+	err := request("POST", "user/me/playlists", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+	playlistId := strconv.Itoa(resp.Id)
+
+	// The description can only be set afterwards. The playlist exists already when that fails, so it is returned
+	// anyway, synced playlists are recognised by their id.
+	params = url.Values{}
+	params.Set("description", description)
+	err = request("POST", "playlist/"+playlistId, params, nil)
+	if err != nil {
+		getLogger().Warn("could not set the description of playlist '", name, "': ", err.Error())
+		return &Playlist{Id: resp.Id, Title: name}, nil
+	}
+
+	return &Playlist{Id: resp.Id, Title: name, Description: description}, nil
+}
+
+func AddTracksToPlaylist(playlistId string, trackIds []string) error {
+	params := url.Values{}
+	params.Set("songs", strings.Join(trackIds, ","))
+	return request("POST", "playlist/"+playlistId+"/tracks", params, nil)
+}
+
+func RemoveTracksFromPlaylist(playlistId string, trackIds []string) error {
+	params := url.Values{}
+	params.Set("songs", strings.Join(trackIds, ","))
+	return request("DELETE", "playlist/"+playlistId+"/tracks", params, nil)
+}
+
+func DeletePlaylist(playlistId string) error {
+	return request("DELETE", "playlist/"+playlistId, url.Values{}, nil)
+}
+
+// Error is an error of the Deezer API, which responds with status 200 for most of them
+type Error struct {
+	Type    string
+	Message string
+	Code    int
+}
+
+func (e *Error) Error() string {
+	return "Deezer " + e.Type + ": " + e.Message
+}
+
+func request(method string, path string, params url.Values, result interface{}) error {
+	stateObj, err := state.GetState()
+	if err != nil {
+		return err
+	}
+	if stateObj.Deezer.AccessToken == "" {
+		return errors.New("not logged in to Deezer")
+	}
+	if !stateObj.Deezer.Expiry.IsZero() && stateObj.Deezer.Expiry.Before(time.Now()) {
+		return errors.New("the Deezer access token expired, log in again")
+	}
+
+	params.Set("access_token", stateObj.Deezer.AccessToken)
+	req, err := http.NewRequest(method, apiURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New("Deezer responded with status " + resp.Status)
+	}
+
+	// Responses are either the result, an error object, or true for changes
+	var body json.RawMessage
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return err
+	}
+	errResp := apiError{}
+	if json.Unmarshal(body, &errResp) == nil && errResp.Error != nil {
+		return &Error{Type: errResp.Error.Type, Message: errResp.Error.Message, Code: errResp.Error.Code}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(body, result)
+}
+
+func getLogger() *zap.SugaredLogger {
+	logger, _ := zap.NewDevelopment()
+	sugar := logger.Sugar()
+	defer func(logger *zap.Logger) {
+		_ = logger.Sync()
+	}(logger)
+	return sugar
+}
@@ -0,0 +1,124 @@
+package deezer
+
+import (
+	"api/internal/provider"
+	"errors"
+	"strconv"
+)
+
+// Provider makes Deezer available as a destination. A track with a known ISRC is taken as is, the advanced search
+// by artist and title is the fallback.
+type Provider struct{}
+
+func toTrack(track Track) provider.Track {
+	artists := []string{track.Artist.Name}
+	for _, contributor := range track.Contributors {
+		if contributor.Name != track.Artist.Name {
+			artists = append(artists, contributor.Name)
+		}
+	}
+	return provider.Track{
+		Id:         strconv.Itoa(track.Id),
+		Name:       track.Title,
+		Artists:    artists,
+		Album:      track.Album.Title,
+		ISRC:       track.ISRC,
+		DurationMs: track.Duration * 1000,
+		Explicit:   track.Explicit,
+	}
+}
+
+func (Provider) FindTracks(tracks []provider.Track) ([]provider.MatchResult, error) {
+	results := make([]provider.MatchResult, 0)
+	for _, track := range tracks {
+		trackId, err := findTrack(track)
+		results = append(results, provider.MatchResult{TrackId: trackId, Err: err})
+	}
+	return results, nil
+}
+
+func findTrack(track provider.Track) (string, error) {
+	logger := getLogger()
+	if track.ISRC != "" {
+		deezerTrack, err := GetTrackByISRC(track.ISRC)
+		if err != nil {
+			return "", err
+		}
+		if deezerTrack != nil {
+			logger.Debug("found track on Deezer by ISRC: ", deezerTrack.Title)
+			return strconv.Itoa(deezerTrack.Id), nil
+		}
+	}
+
+	artist := ""
+	if len(track.Artists) > 0 {
+		artist = track.Artists[0]
+	}
+	candidates, err := SearchTracks(artist, provider.NormalizeTitle(track.Name))
+	if err != nil {
+		return "", err
+	}
+	for _, candidate := range candidates {
+		if provider.IsSameTrack(track, toTrack(candidate)) {
+			logger.Debug("found track on Deezer by search: ", candidate.Title)
+			return strconv.Itoa(candidate.Id), nil
+		}
+	}
+	return "", nil
+}
+
+// GetSyncedPlaylist checks the playlist that was created before, the list of Deezer playlists has no descriptions
+func (Provider) GetSyncedPlaylist(_ string, playlistId string) (*provider.Playlist, error) {
+	if playlistId == "" {
+		return nil, nil
+	}
+
+	playlist, err := GetPlaylist(playlistId)
+	var deezerErr *Error
+	if errors.As(err, &deezerErr) && deezerErr.Code == errNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	item := toPlaylist(*playlist)
+	return &item, nil
+}
+
+func (p Provider) CreatePlaylist(name string, sourcePlaylistId string) (*provider.Playlist, error) {
+	return p.CreateNamedPlaylist(name, name+". Synced with spync. (ID: "+sourcePlaylistId+")")
+}
+
+func (Provider) CreateNamedPlaylist(name string, description string) (*provider.Playlist, error) {
+	playlist, err := CreatePlaylist(name, description)
+	if err != nil {
+		return nil, err
+	}
+
+	item := toPlaylist(*playlist)
+	return &item, nil
+}
+
+func (Provider) GetPlaylistTrackIds(playlistId string) ([]string, error) {
+	return GetPlaylistTrackIds(playlistId)
+}
+
+func (Provider) AddTracks(playlistId string, trackIds []string) error {
+	return AddTracksToPlaylist(playlistId, trackIds)
+}
+
+func (Provider) RemoveTracks(playlistId string, trackIds []string) error {
+	return RemoveTracksFromPlaylist(playlistId, trackIds)
+}
+
+func (Provider) DeletePlaylist(playlistId string) error {
+	return DeletePlaylist(playlistId)
+}
+
+func toPlaylist(playlist Playlist) provider.Playlist {
+	return provider.Playlist{
+		Id:          strconv.Itoa(playlist.Id),
+		Name:        playlist.Title,
+		Description: playlist.Description,
+	}
+}
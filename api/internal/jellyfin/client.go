@@ -0,0 +1,194 @@
+package jellyfin
+
+import (
+	"api/internal/configuration"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ticksPerMs converts the run time of Jellyfin items, which is in ticks of 100 nanoseconds
+const ticksPerMs = 10000
+
+type Item struct {
+	Id           string            `json:"Id"`
+	Name         string            `json:"Name"`
+	Album        string            `json:"Album"`
+	Artists      []string          `json:"Artists"`
+	RunTimeTicks int64             `json:"RunTimeTicks"`
+	ProviderIds  map[string]string `json:"ProviderIds"`
+	// PlaylistItemId identifies the entry of the item when it is listed as part of a playlist
+	PlaylistItemId string `json:"PlaylistItemId"`
+}
+
+type itemsResponse struct {
+	Items []Item `json:"Items"`
+}
+
+// errNotFound is returned when Jellyfin responds with a 404
+var errNotFound = errors.New("not found in Jellyfin")
+
+func SearchTracks(term string) ([]Item, error) {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("userId", config.Jellyfin.UserId)
+	params.Set("searchTerm", term)
+	params.Set("includeItemTypes", "Audio")
+	params.Set("recursive", "true")
+	params.Set("fields", "ProviderIds")
+	params.Set("limit", "20")
+
+	var resp itemsResponse
+	err = request("GET", "Items?"+params.Encode(), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+func GetPlaylistItems(playlistId string) ([]Item, error) {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("userId", config.Jellyfin.UserId)
+
+	var resp itemsResponse
+	err = request("GET", "Playlists/"+playlistId+"/Items?"+params.Encode(), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+func CreatePlaylist(name string) (string, error) {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return "", err
+	}
+
+	body := map[string]interface{}{
+		"Name":      name,
+		"UserId":    config.Jellyfin.UserId,
+		"MediaType": "Audio",
+	}
+	var resp struct {
+		Id string `json:"Id"`
+	}
+	err = request("POST", "Playlists", body, &resp)
+	if err != nil {
+		return "", err
+	}
+	return resp.Id, nil
+}
+
+func AddToPlaylist(playlistId string, itemIds []string) error {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("userId", config.Jellyfin.UserId)
+	params.Set("ids", strings.Join(itemIds, ","))
+	return request("POST", "Playlists/"+playlistId+"/Items?"+params.Encode(), nil, nil)
+}
+
+// RemoveFromPlaylist removes every entry of the items from the playlist
+func RemoveFromPlaylist(playlistId string, itemIds []string) error {
+	items, err := GetPlaylistItems(playlistId)
+	if err != nil {
+		return err
+	}
+
+	remove := make(map[string]bool)
+	for _, itemId := range itemIds {
+		remove[itemId] = true
+	}
+	entryIds := make([]string, 0)
+	for _, item := range items {
+		if remove[item.Id] {
+			entryIds = append(entryIds, item.PlaylistItemId)
+		}
+	}
+	if len(entryIds) == 0 {
+		return nil
+	}
+
+	params := url.Values{}
+	params.Set("entryIds", strings.Join(entryIds, ","))
+	return request("DELETE", "Playlists/"+playlistId+"/Items?"+params.Encode(), nil, nil)
+}
+
+// DeletePlaylist deletes the playlist, playlists are items like any other in Jellyfin
+func DeletePlaylist(playlistId string) error {
+	return request("DELETE", "Items/"+playlistId, nil, nil)
+}
+
+func request(method string, path string, body interface{}, result interface{}) error {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return err
+	}
+	if config.Jellyfin.Url == "" {
+		return errors.New("no Jellyfin server configured")
+	}
+
+	var reader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequest(method, strings.TrimSuffix(config.Jellyfin.Url, "/")+"/"+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", `MediaBrowser Client="spync", Token="`+config.Jellyfin.ApiKey+`"`)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New("Jellyfin responded with status " + resp.Status)
+	}
+	if result == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func getLogger() *zap.SugaredLogger {
+	logger, _ := zap.NewDevelopment()
+	sugar := logger.Sugar()
+	defer func(logger *zap.Logger) {
+		_ = logger.Sync()
+	}(logger)
+	return sugar
+}
@@ -0,0 +1,102 @@
+package jellyfin
+
+import (
+	"api/internal/provider"
+	"errors"
+)
+
+// Provider makes a Jellyfin music library available as a destination
+type Provider struct{}
+
+func toTrack(item Item) provider.Track {
+	return provider.Track{
+		Id:         item.Id,
+		Name:       item.Name,
+		Artists:    item.Artists,
+		Album:      item.Album,
+		ISRC:       item.ProviderIds["ISRC"],
+		DurationMs: int(item.RunTimeTicks / ticksPerMs),
+	}
+}
+
+func (Provider) FindTracks(tracks []provider.Track) ([]provider.MatchResult, error) {
+	logger := getLogger()
+	results := make([]provider.MatchResult, 0)
+	for _, track := range tracks {
+		items, err := SearchTracks(provider.NormalizeTitle(track.Name))
+		if err != nil {
+			results = append(results, provider.MatchResult{Err: err})
+			continue
+		}
+
+		result := provider.MatchResult{}
+		for _, item := range items {
+			if provider.IsSameTrack(track, toTrack(item)) {
+				logger.Debug("found track in Jellyfin library: ", item.Name)
+				result.TrackId = item.Id
+				break
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// GetSyncedPlaylist checks the playlist that was created for the source playlist, Jellyfin playlists have no
+// description to find it by. A playlist that was removed in Jellyfin is created again.
+func (Provider) GetSyncedPlaylist(_ string, playlistId string) (*provider.Playlist, error) {
+	if playlistId == "" {
+		return nil, nil
+	}
+
+	_, err := GetPlaylistItems(playlistId)
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &provider.Playlist{Id: playlistId}, nil
+}
+
+func (Provider) CreatePlaylist(name string, sourcePlaylistId string) (*provider.Playlist, error) {
+	playlistId, err := CreatePlaylist(name)
+	if err != nil {
+		return nil, err
+	}
+	return &provider.Playlist{Id: playlistId, Name: name}, nil
+}
+
+func (Provider) GetPlaylistTrackIds(playlistId string) ([]string, error) {
+	items, err := GetPlaylistItems(playlistId)
+	if err != nil {
+		return nil, err
+	}
+
+	trackIds := make([]string, 0)
+	for _, item := range items {
+		trackIds = append(trackIds, item.Id)
+	}
+	return trackIds, nil
+}
+
+func (Provider) AddTracks(playlistId string, trackIds []string) error {
+	return AddToPlaylist(playlistId, trackIds)
+}
+
+// CreateNamedPlaylist creates the playlist without the description, Jellyfin playlists do not have one
+func (Provider) CreateNamedPlaylist(name string, description string) (*provider.Playlist, error) {
+	playlistId, err := CreatePlaylist(name)
+	if err != nil {
+		return nil, err
+	}
+	return &provider.Playlist{Id: playlistId, Name: name}, nil
+}
+
+func (Provider) RemoveTracks(playlistId string, trackIds []string) error {
+	return RemoveFromPlaylist(playlistId, trackIds)
+}
+
+func (Provider) DeletePlaylist(playlistId string) error {
+	return DeletePlaylist(playlistId)
+}
@@ -0,0 +1,189 @@
+package local
+
+import (
+	"api/internal/provider"
+	"errors"
+	"github.com/dhowden/tag"
+	"go.uber.org/zap"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var extensions = map[string]bool{
+	".mp3":  true,
+	".flac": true,
+	".ogg":  true,
+	".opus": true,
+	".m4a":  true,
+	".mp4":  true,
+	".alac": true,
+}
+
+// Library is the music directory, the ids of the tracks are their paths relative to the directory
+type Library struct {
+	Directory string
+	Tracks    []provider.Track
+	byISRC    map[string]provider.Track
+	byTitle   map[string][]provider.Track
+}
+
+// ScanLibrary reads the tags of every audio file in the directory. Files without readable tags are left out.
+func ScanLibrary(directory string) (*Library, error) {
+	logger := getLogger()
+	directory, err := filepath.Abs(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	library := &Library{
+		Directory: directory,
+		Tracks:    make([]provider.Track, 0),
+		byISRC:    make(map[string]provider.Track),
+		byTitle:   make(map[string][]provider.Track),
+	}
+	err = filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !extensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		track, err := readTrack(path)
+		if err != nil {
+			logger.Debug("could not read tags of ", path, ": ", err.Error())
+			return nil
+		}
+		track.Id, err = filepath.Rel(directory, path)
+		if err != nil {
+			return err
+		}
+		library.add(track)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("scanned music directory. tracks: ", len(library.Tracks))
+
+	return library, nil
+}
+
+// FindTrack returns the local file of the track, the id is empty when the track is missing
+func (l *Library) FindTrack(track provider.Track) provider.Track {
+	if track.ISRC != "" {
+		if localTrack, ok := l.byISRC[strings.ToUpper(track.ISRC)]; ok {
+			return localTrack
+		}
+	}
+
+	for _, localTrack := range l.byTitle[provider.NormalizeTitle(track.Name)] {
+		if provider.IsSameTrack(track, localTrack) {
+			return localTrack
+		}
+	}
+	return provider.Track{}
+}
+
+func (l *Library) add(track provider.Track) {
+	l.Tracks = append(l.Tracks, track)
+	if track.ISRC != "" {
+		l.byISRC[strings.ToUpper(track.ISRC)] = track
+	}
+	title := provider.NormalizeTitle(track.Name)
+	l.byTitle[title] = append(l.byTitle[title], track)
+}
+
+func readTrack(path string) (provider.Track, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return provider.Track{}, err
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	metadata, err := tag.ReadFrom(file)
+	if err != nil {
+		return provider.Track{}, err
+	}
+
+	track := provider.Track{
+		Name:    metadata.Title(),
+		Artists: make([]string, 0),
+		Album:   metadata.Album(),
+	}
+	if track.Name == "" {
+		track.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if metadata.Artist() != "" {
+		track.Artists = append(track.Artists, metadata.Artist())
+	}
+	if metadata.AlbumArtist() != "" && metadata.AlbumArtist() != metadata.Artist() {
+		track.Artists = append(track.Artists, metadata.AlbumArtist())
+	}
+
+	// The ISRC is stored as TSRC in ID3 tags and as ISRC in Vorbis comments and MP4 atoms
+	for key, value := range metadata.Raw() {
+		text, ok := value.(string)
+		if !ok {
+			continue
+		}
+		switch strings.ToUpper(key) {
+		case "TSRC", "ISRC":
+			track.ISRC = strings.TrimSpace(text)
+		case "TLEN":
+			track.DurationMs, _ = strconv.Atoi(strings.TrimSpace(text))
+		}
+	}
+
+	// The tags only contain the duration when an ID3 TLEN frame is present. FLAC files always describe their stream,
+	// other files without TLEN are left without a duration, which leaves it out of the comparison when matching.
+	if track.DurationMs == 0 && metadata.FileType() == tag.FLAC {
+		track.DurationMs, err = readFLACDuration(file)
+		if err != nil {
+			logger := getLogger()
+			logger.Debug("could not read duration of ", path, ": ", err.Error())
+		}
+	}
+	return track, nil
+}
+
+// readFLACDuration calculates the duration from the STREAMINFO block, which is always the first metadata block
+func readFLACDuration(file io.ReadSeeker) (int, error) {
+	_, err := file.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	header := make([]byte, 8+34)
+	_, err = io.ReadFull(file, header)
+	if err != nil {
+		return 0, err
+	}
+	if string(header[:4]) != "fLaC" || header[4]&0x7F != 0 {
+		return 0, errors.New("no FLAC stream info")
+	}
+
+	streamInfo := header[8:]
+	sampleRate := int64(streamInfo[10])<<12 | int64(streamInfo[11])<<4 | int64(streamInfo[12])>>4
+	samples := int64(streamInfo[13]&0x0F)<<32 | int64(streamInfo[14])<<24 | int64(streamInfo[15])<<16 |
+		int64(streamInfo[16])<<8 | int64(streamInfo[17])
+	if sampleRate == 0 {
+		return 0, errors.New("FLAC stream info without sample rate")
+	}
+	return int(samples * 1000 / sampleRate), nil
+}
+
+func getLogger() *zap.SugaredLogger {
+	logger, _ := zap.NewDevelopment()
+	sugar := logger.Sugar()
+	defer func(logger *zap.Logger) {
+		_ = logger.Sync()
+	}(logger)
+	return sugar
+}
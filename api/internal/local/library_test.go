@@ -0,0 +1,55 @@
+package local
+
+import (
+	"bytes"
+	"testing"
+)
+
+// newFLACHeader returns the marker and the STREAMINFO block of a FLAC file, 16 bits stereo
+func newFLACHeader(blockType byte, sampleRate int64, samples int64) []byte {
+	header := make([]byte, 8+34)
+	copy(header, "fLaC")
+	header[4] = blockType
+	header[7] = 34
+
+	streamInfo := header[8:]
+	streamInfo[10] = byte(sampleRate >> 12)
+	streamInfo[11] = byte(sampleRate >> 4)
+	streamInfo[12] = byte(sampleRate<<4) | 1<<1
+	streamInfo[13] = 15<<4 | byte(samples>>32)&0x0F
+	streamInfo[14] = byte(samples >> 24)
+	streamInfo[15] = byte(samples >> 16)
+	streamInfo[16] = byte(samples >> 8)
+	streamInfo[17] = byte(samples)
+	return header
+}
+
+func TestReadFLACDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    int
+		wantErr bool
+	}{
+		{name: "44.1 kHz", data: newFLACHeader(0, 44100, 44100*200), want: 200000},
+		{name: "96 kHz with a partial second", data: newFLACHeader(0, 96000, 96000*3+48000), want: 3500},
+		{name: "more than 32 bits of samples", data: newFLACHeader(0, 192000, 192000*30000), want: 30000000},
+		{name: "last metadata block", data: newFLACHeader(0x80, 48000, 48000*60), want: 60000},
+		{name: "no FLAC marker", data: append([]byte("ID3"), make([]byte, 39)...), wantErr: true},
+		{name: "other block first", data: newFLACHeader(4, 44100, 44100), wantErr: true},
+		{name: "no sample rate", data: newFLACHeader(0, 0, 44100), wantErr: true},
+		{name: "too short", data: []byte("fLaC"), wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := readFLACDuration(bytes.NewReader(test.data))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("readFLACDuration() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("readFLACDuration() = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package local
+
+import (
+	"api/internal/configuration"
+	"api/internal/provider"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var unsafeCharacters = regexp.MustCompile(`[<>:"/\\|?*\x00-\x1f]`)
+
+type xspfPlaylist struct {
+	XMLName    xml.Name    `xml:"playlist"`
+	Version    string      `xml:"version,attr"`
+	Namespace  string      `xml:"xmlns,attr"`
+	Title      string      `xml:"title"`
+	Annotation string      `xml:"annotation"`
+	Tracks     []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location string `xml:"location"`
+	Title    string `xml:"title"`
+	Creator  string `xml:"creator"`
+	Album    string `xml:"album,omitempty"`
+	Duration int    `xml:"duration,omitempty"`
+}
+
+// GetPlaylistPath returns the file a playlist is written to, the name is made safe for every file system
+func GetPlaylistPath(directory string, name string, format string) string {
+	if format != configuration.FormatXSPF {
+		format = configuration.FormatM3U8
+	}
+	name = strings.TrimSpace(unsafeCharacters.ReplaceAllString(name, "_"))
+	return filepath.Join(directory, name+"."+format)
+}
+
+// WritePlaylist replaces the playlist file with the tracks of the library. The file is written next to the old
+// one first, so players never see a partial playlist.
+func WritePlaylist(path string, name string, description string, library *Library, tracks []provider.Track) error {
+	directory, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(directory, 0755)
+	if err != nil {
+		return err
+	}
+
+	// Paths are relative to the playlist, so the music and the playlists can be moved together
+	locations := make([]string, 0)
+	for _, track := range tracks {
+		location, err := filepath.Rel(directory, filepath.Join(library.Directory, track.Id))
+		if err != nil {
+			return err
+		}
+		locations = append(locations, filepath.ToSlash(location))
+	}
+
+	var content []byte
+	if strings.HasSuffix(path, "."+configuration.FormatXSPF) {
+		content, err = getXSPF(name, description, tracks, locations)
+		if err != nil {
+			return err
+		}
+	} else {
+		content = getM3U8(name, tracks, locations)
+	}
+
+	file, err := os.CreateTemp(directory, ".spync-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(file.Name())
+	}()
+
+	_, err = file.Write(content)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(file.Name(), 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func getM3U8(name string, tracks []provider.Track, locations []string) []byte {
+	var builder strings.Builder
+	builder.WriteString("#EXTM3U\n")
+	builder.WriteString("#PLAYLIST:" + name + "\n")
+	for i, track := range tracks {
+		builder.WriteString(fmt.Sprintf("#EXTINF:%d,%s - %s\n", track.DurationMs/1000, track.ArtistNames(), track.Name))
+		builder.WriteString(locations[i] + "\n")
+	}
+	return []byte(builder.String())
+}
+
+func getXSPF(name string, description string, tracks []provider.Track, locations []string) ([]byte, error) {
+	playlist := xspfPlaylist{
+		Version:    "1",
+		Namespace:  "http://xspf.org/ns/0/",
+		Title:      name,
+		Annotation: description,
+		Tracks:     make([]xspfTrack, 0),
+	}
+	for i, track := range tracks {
+		location := url.URL{Path: locations[i]}
+		playlist.Tracks = append(playlist.Tracks, xspfTrack{
+			Location: location.String(),
+			Title:    track.Name,
+			Creator:  track.ArtistNames(),
+			Album:    track.Album,
+			Duration: track.DurationMs,
+		})
+	}
+
+	content, err := xml.MarshalIndent(playlist, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(content, '\n')...), nil
+}
@@ -0,0 +1,226 @@
+package plex
+
+import (
+	"api/internal/configuration"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type Metadata struct {
+	RatingKey string `json:"ratingKey"`
+	Title     string `json:"title"`
+	Summary   string `json:"summary"`
+	// The artist of the album, the artist of the track is only set when it differs
+	GrandparentTitle string `json:"grandparentTitle"`
+	OriginalTitle    string `json:"originalTitle"`
+	ParentTitle      string `json:"parentTitle"`
+	Duration         int    `json:"duration"`
+	// PlaylistItemId identifies the entry of the track when it is listed as part of a playlist
+	PlaylistItemId int `json:"playlistItemID"`
+}
+
+type mediaContainer struct {
+	MediaContainer struct {
+		MachineIdentifier string     `json:"machineIdentifier"`
+		Metadata          []Metadata `json:"Metadata"`
+	} `json:"MediaContainer"`
+}
+
+func SearchTracks(query string) ([]Metadata, error) {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("type", "10")
+	params.Set("title", query)
+	resp, err := request("GET", "library/sections/"+config.Plex.SectionId+"/all", params)
+	if err != nil {
+		return nil, err
+	}
+	return resp.MediaContainer.Metadata, nil
+}
+
+func GetPlaylists() ([]Metadata, error) {
+	params := url.Values{}
+	params.Set("playlistType", "audio")
+	resp, err := request("GET", "playlists", params)
+	if err != nil {
+		return nil, err
+	}
+	return resp.MediaContainer.Metadata, nil
+}
+
+func GetPlaylistItems(playlistId string) ([]Metadata, error) {
+	resp, err := request("GET", "playlists/"+playlistId+"/items", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.MediaContainer.Metadata, nil
+}
+
+// CreatePlaylist creates an empty audio playlist. Plex only creates playlists together with tracks, so it is created
+// with a track of the library which is removed again right away.
+func CreatePlaylist(name string, summary string) (string, error) {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("type", "10")
+	params.Set("X-Plex-Container-Start", "0")
+	params.Set("X-Plex-Container-Size", "1")
+	resp, err := request("GET", "library/sections/"+config.Plex.SectionId+"/all", params)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.MediaContainer.Metadata) == 0 {
+		return "", errors.New("the Plex library has no tracks to create the playlist with")
+	}
+	uri, err := getItemsUri([]string{resp.MediaContainer.Metadata[0].RatingKey})
+	if err != nil {
+		return "", err
+	}
+
+	params = url.Values{}
+	params.Set("type", "audio")
+	params.Set("title", name)
+	params.Set("smart", "0")
+	params.Set("uri", uri)
+	resp, err = request("POST", "playlists", params)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.MediaContainer.Metadata) == 0 {
+		return "", errors.New("Plex did not return the created playlist")
+	}
+	playlistId := resp.MediaContainer.Metadata[0].RatingKey
+
+	items, err := GetPlaylistItems(playlistId)
+	if err != nil {
+		return "", err
+	}
+	for _, item := range items {
+		_, err = request("DELETE", "playlists/"+playlistId+"/items/"+strconv.Itoa(item.PlaylistItemId), url.Values{})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// The summary can only be set afterwards. The playlist exists already when that fails, so the id is returned
+	// anyway and the playlist is recognised by its id afterwards.
+	params = url.Values{}
+	params.Set("summary", summary)
+	_, err = request("PUT", "playlists/"+playlistId, params)
+	if err != nil {
+		getLogger().Warn("could not set the summary of playlist '", name, "': ", err.Error())
+	}
+
+	return playlistId, nil
+}
+
+func AddToPlaylist(playlistId string, trackIds []string) error {
+	uri, err := getItemsUri(trackIds)
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("uri", uri)
+	_, err = request("PUT", "playlists/"+playlistId+"/items", params)
+	return err
+}
+
+// RemoveFromPlaylist removes every entry of the tracks, Plex removes the entries one by one
+func RemoveFromPlaylist(playlistId string, trackIds []string) error {
+	items, err := GetPlaylistItems(playlistId)
+	if err != nil {
+		return err
+	}
+
+	remove := make(map[string]bool)
+	for _, trackId := range trackIds {
+		remove[trackId] = true
+	}
+	for _, item := range items {
+		if !remove[item.RatingKey] {
+			continue
+		}
+		_, err = request("DELETE", "playlists/"+playlistId+"/items/"+strconv.Itoa(item.PlaylistItemId), url.Values{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func DeletePlaylist(playlistId string) error {
+	_, err := request("DELETE", "playlists/"+playlistId, url.Values{})
+	return err
+}
+
+// getItemsUri returns the uri Plex uses to refer to library items of this server
+func getItemsUri(trackIds []string) (string, error) {
+	resp, err := request("GET", "identity", url.Values{})
+	if err != nil {
+		return "", err
+	}
+
+	return "server://" + resp.MediaContainer.MachineIdentifier + "/com.plexapp.plugins.library/library/metadata/" +
+		strings.Join(trackIds, ","), nil
+}
+
+func request(method string, path string, params url.Values) (*mediaContainer, error) {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	if config.Plex.Url == "" {
+		return nil, errors.New("no Plex server configured")
+	}
+
+	req, err := http.NewRequest(method, strings.TrimSuffix(config.Plex.Url, "/")+"/"+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Plex-Token", config.Plex.Token)
+	req.Header.Set("X-Plex-Client-Identifier", "spync")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, errors.New("Plex responded with status " + resp.Status)
+	}
+
+	container := &mediaContainer{}
+	if resp.ContentLength == 0 {
+		return container, nil
+	}
+	err = json.NewDecoder(resp.Body).Decode(container)
+	if err != nil {
+		return nil, err
+	}
+	return container, nil
+}
+
+func getLogger() *zap.SugaredLogger {
+	logger, _ := zap.NewDevelopment()
+	sugar := logger.Sugar()
+	defer func(logger *zap.Logger) {
+		_ = logger.Sync()
+	}(logger)
+	return sugar
+}
@@ -0,0 +1,98 @@
+package plex
+
+import (
+	"api/internal/provider"
+	"strings"
+)
+
+// Provider makes a Plex music library available as a destination
+type Provider struct{}
+
+func toTrack(metadata Metadata) provider.Track {
+	artist := metadata.OriginalTitle
+	if artist == "" {
+		artist = metadata.GrandparentTitle
+	}
+	return provider.Track{
+		Id:         metadata.RatingKey,
+		Name:       metadata.Title,
+		Artists:    []string{artist},
+		Album:      metadata.ParentTitle,
+		DurationMs: metadata.Duration,
+	}
+}
+
+func (Provider) FindTracks(tracks []provider.Track) ([]provider.MatchResult, error) {
+	logger := getLogger()
+	results := make([]provider.MatchResult, 0)
+	for _, track := range tracks {
+		items, err := SearchTracks(provider.NormalizeTitle(track.Name))
+		if err != nil {
+			results = append(results, provider.MatchResult{Err: err})
+			continue
+		}
+
+		result := provider.MatchResult{}
+		for _, item := range items {
+			if provider.IsSameTrack(track, toTrack(item)) {
+				logger.Debug("found track in Plex library: ", item.Title)
+				result.TrackId = item.RatingKey
+				break
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// GetSyncedPlaylist also recognises the playlist by its id, in case its summary could not be set
+func (Provider) GetSyncedPlaylist(sourcePlaylistId string, playlistId string) (*provider.Playlist, error) {
+	playlists, err := GetPlaylists()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, playlist := range playlists {
+		if (playlistId != "" && playlist.RatingKey == playlistId) || strings.Contains(playlist.Summary, "(ID: "+sourcePlaylistId+")") {
+			return &provider.Playlist{Id: playlist.RatingKey, Name: playlist.Title, Description: playlist.Summary}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (p Provider) CreatePlaylist(name string, sourcePlaylistId string) (*provider.Playlist, error) {
+	return p.CreateNamedPlaylist(name, name+". Synced with spync. (ID: "+sourcePlaylistId+")")
+}
+
+func (Provider) CreateNamedPlaylist(name string, description string) (*provider.Playlist, error) {
+	playlistId, err := CreatePlaylist(name, description)
+	if err != nil {
+		return nil, err
+	}
+	return &provider.Playlist{Id: playlistId, Name: name, Description: description}, nil
+}
+
+func (Provider) GetPlaylistTrackIds(playlistId string) ([]string, error) {
+	items, err := GetPlaylistItems(playlistId)
+	if err != nil {
+		return nil, err
+	}
+
+	trackIds := make([]string, 0)
+	for _, item := range items {
+		trackIds = append(trackIds, item.RatingKey)
+	}
+	return trackIds, nil
+}
+
+func (Provider) AddTracks(playlistId string, trackIds []string) error {
+	return AddToPlaylist(playlistId, trackIds)
+}
+
+func (Provider) RemoveTracks(playlistId string, trackIds []string) error {
+	return RemoveFromPlaylist(playlistId, trackIds)
+}
+
+func (Provider) DeletePlaylist(playlistId string) error {
+	return DeletePlaylist(playlistId)
+}
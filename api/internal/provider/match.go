@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxDurationDifferenceMs is how much the durations of two recordings of the same track may differ
+const maxDurationDifferenceMs = 5000
+
+var featuringRegex = regexp.MustCompile(`\s*[(\[](?:feat\.|ft\.|with) [^)\]]+[)\]]`)
+
+// IsSameTrack decides whether a candidate of a destination is the track. A matching ISRC is enough, otherwise the
+// title, one of the artists and the duration have to match.
+func IsSameTrack(track Track, candidate Track) bool {
+	if track.ISRC != "" && strings.EqualFold(track.ISRC, candidate.ISRC) {
+		return true
+	}
+
+	if NormalizeTitle(track.Name) != NormalizeTitle(candidate.Name) {
+		return false
+	}
+
+	if track.DurationMs > 0 && candidate.DurationMs > 0 {
+		difference := track.DurationMs - candidate.DurationMs
+		if difference < -maxDurationDifferenceMs || difference > maxDurationDifferenceMs {
+			return false
+		}
+	}
+
+	candidateArtists := strings.ToLower(candidate.ArtistNames())
+	for _, artist := range track.Artists {
+		if artist != "" && strings.Contains(candidateArtists, strings.ToLower(artist)) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTitle removes the featured artists from a title, services do not agree on where to put them
+func NormalizeTitle(title string) string {
+	return strings.ToLower(strings.TrimSpace(featuringRegex.ReplaceAllString(title, "")))
+}
@@ -0,0 +1,106 @@
+package provider
+
+import "testing"
+
+func TestIsSameTrack(t *testing.T) {
+	track := Track{Name: "Song", Artists: []string{"Artist", "Other"}, ISRC: "USABC1234567", DurationMs: 200000}
+
+	tests := []struct {
+		name      string
+		track     Track
+		candidate Track
+		want      bool
+	}{
+		{
+			name:      "same isrc",
+			track:     track,
+			candidate: Track{Name: "Something else", Artists: []string{"Someone"}, ISRC: "usabc1234567"},
+			want:      true,
+		},
+		{
+			name:      "title and artist",
+			track:     track,
+			candidate: Track{Name: "song", Artists: []string{"The Artist"}, DurationMs: 201000},
+			want:      true,
+		},
+		{
+			name:      "second artist",
+			track:     track,
+			candidate: Track{Name: "Song", Artists: []string{"other"}},
+			want:      true,
+		},
+		{
+			name:      "featured artist in title",
+			track:     track,
+			candidate: Track{Name: "Song (feat. Guest)", Artists: []string{"Artist"}},
+			want:      true,
+		},
+		{
+			name:      "unknown duration",
+			track:     Track{Name: "Song", Artists: []string{"Artist"}},
+			candidate: Track{Name: "Song", Artists: []string{"Artist"}, DurationMs: 300000},
+			want:      true,
+		},
+		{
+			name:      "different title",
+			track:     track,
+			candidate: Track{Name: "Song Remix", Artists: []string{"Artist"}, DurationMs: 200000},
+			want:      false,
+		},
+		{
+			name:      "different artist",
+			track:     track,
+			candidate: Track{Name: "Song", Artists: []string{"Someone"}, DurationMs: 200000},
+			want:      false,
+		},
+		{
+			name:      "different duration",
+			track:     track,
+			candidate: Track{Name: "Song", Artists: []string{"Artist"}, DurationMs: 206000},
+			want:      false,
+		},
+		{
+			name:      "different isrc",
+			track:     track,
+			candidate: Track{Name: "Other", Artists: []string{"Artist"}, ISRC: "USABC7654321"},
+			want:      false,
+		},
+		{
+			name:      "empty artist",
+			track:     Track{Name: "Song", Artists: []string{""}},
+			candidate: Track{Name: "Song", Artists: []string{"Artist"}},
+			want:      false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsSameTrack(test.track, test.candidate); got != test.want {
+				t.Errorf("IsSameTrack() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{title: "Song", want: "song"},
+		{title: "  Song  ", want: "song"},
+		{title: "Song (feat. Guest)", want: "song"},
+		{title: "Song [ft. Guest & Other]", want: "song"},
+		{title: "Song (with Guest)", want: "song"},
+		{title: "Song (Live)", want: "song (live)"},
+		{title: "Song (feat. Guest) - Remix", want: "song - remix"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			if got := NormalizeTitle(test.title); got != test.want {
+				t.Errorf("NormalizeTitle(%q) = %q, want %q", test.title, got, test.want)
+			}
+		})
+	}
+}
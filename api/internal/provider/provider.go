@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"api/internal/state"
+	"errors"
+	"strings"
+	"time"
+)
+
+// MaxTracksPerRequest is the amount of tracks a destination receives in a single call to AddTracks
+const MaxTracksPerRequest = 100
+
+// MaxAlbumsPerRequest is the amount of albums an album destination receives in a single call to AddAlbums
+const MaxAlbumsPerRequest = 100
+
+// ErrNotSupported is returned by providers for operations their service does not offer
+var ErrNotSupported = errors.New("not supported by this provider")
+
+// Track is a track of any provider. AddedAt is zero when the provider does not know when the track was added.
+// Local tracks only exist on the device of the user, they can not be added to or removed from playlists.
+type Track struct {
+	Id         string
+	Name       string
+	Artists    []string
+	Album      string
+	ISRC       string
+	DurationMs int
+	Explicit   bool
+	AddedAt    time.Time
+	IsLocal    bool
+}
+
+func (t Track) ArtistNames() string {
+	return strings.Join(t.Artists, " ")
+}
+
+// ParseISODuration reads ISO 8601 durations such as PT3M21S into milliseconds, it returns 0 when the duration can
+// not be read
+func ParseISODuration(isoDuration string) int {
+	duration, err := time.ParseDuration(strings.ToLower(strings.TrimPrefix(isoDuration, "PT")))
+	if err != nil {
+		return 0
+	}
+	return int(duration.Milliseconds())
+}
+
+// Playlist is a playlist of any provider. The snapshot id changes with every change of the playlist, it is empty
+// when the provider does not have one.
+type Playlist struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Owner       string `json:"owner"`
+	Description string `json:"description"`
+	SnapshotId  string `json:"-"`
+}
+
+type Album struct {
+	Id      string
+	Name    string
+	Artists []string
+	UPC     string
+	AddedAt time.Time
+}
+
+func (a Album) ArtistNames() string {
+	return strings.Join(a.Artists, " ")
+}
+
+// MatchResult is the id of the matched track or album, which is empty when it was not found
+type MatchResult struct {
+	TrackId string
+	Err     error
+}
+
+// Source is a service that playlists are synced from
+type Source interface {
+	GetPlaylists() ([]Playlist, error)
+	GetPlaylist(playlistId string) (*Playlist, error)
+	// GetPlaylistTracks returns the tracks that can be synced, and the tracks that are skipped with the reason
+	GetPlaylistTracks(playlistId string) ([]Track, []state.TrackReport, error)
+}
+
+// Destination is a service that playlists are synced to. Synced playlists are found by the id of the source
+// playlist.
+type Destination interface {
+	// FindTracks returns a result for every track, in the same order
+	FindTracks(tracks []Track) ([]MatchResult, error)
+	// GetSyncedPlaylist returns nil when the playlist does not exist (anymore). The playlist id is the playlist that
+	// was created for the source playlist before, or empty. Destinations that can not find playlists by their
+	// description rely on it.
+	GetSyncedPlaylist(sourcePlaylistId string, playlistId string) (*Playlist, error)
+	CreatePlaylist(name string, sourcePlaylistId string) (*Playlist, error)
+	// CreateNamedPlaylist creates a playlist that does not belong to a single source playlist, such as an archive
+	CreateNamedPlaylist(name string, description string) (*Playlist, error)
+	GetPlaylistTrackIds(playlistId string) ([]string, error)
+	AddTracks(playlistId string, trackIds []string) error
+	// RemoveTracks removes every occurrence of the tracks from the playlist
+	RemoveTracks(playlistId string, trackIds []string) error
+	DeletePlaylist(playlistId string) error
+}
+
+// Library is implemented by destinations that keep a library of songs besides the playlists
+type Library interface {
+	AddToLibrary(trackIds []string) error
+}
+
+// AlbumSource is implemented by sources that have saved albums
+type AlbumSource interface {
+	GetSavedAlbums() ([]Album, error)
+}
+
+// AlbumDestination is implemented by destinations that albums can be saved to
+type AlbumDestination interface {
+	// FindAlbums returns a result for every album, in the same order
+	FindAlbums(albums []Album) ([]MatchResult, error)
+	AddAlbums(albumIds []string) error
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"errors"
+	"sort"
+)
+
+const (
+	Spotify    = "spotify"
+	AppleMusic = "apple-music"
+	Subsonic   = "subsonic"
+	Jellyfin   = "jellyfin"
+	Plex       = "plex"
+	Deezer     = "deezer"
+	Tidal      = "tidal"
+	YouTube    = "youtube-music"
+)
+
+var sources = make(map[string]Source)
+var destinations = make(map[string]Destination)
+
+func RegisterSource(name string, source Source) {
+	sources[name] = source
+}
+
+func RegisterDestination(name string, destination Destination) {
+	destinations[name] = destination
+}
+
+func GetSource(name string) (Source, error) {
+	source, ok := sources[name]
+	if !ok {
+		return nil, errors.New("unknown source: " + name)
+	}
+	return source, nil
+}
+
+func GetDestination(name string) (Destination, error) {
+	destination, ok := destinations[name]
+	if !ok {
+		return nil, errors.New("unknown destination: " + name)
+	}
+	return destination, nil
+}
+
+// GetNames returns the names of the registered sources and destinations
+func GetNames() ([]string, []string) {
+	sourceNames := make([]string, 0)
+	for name := range sources {
+		sourceNames = append(sourceNames, name)
+	}
+	sort.Strings(sourceNames)
+
+	destinationNames := make([]string, 0)
+	for name := range destinations {
+		destinationNames = append(destinationNames, name)
+	}
+	sort.Strings(destinationNames)
+
+	return sourceNames, destinationNames
+}
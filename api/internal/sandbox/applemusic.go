@@ -0,0 +1,269 @@
+package sandbox
+
+import (
+	"encoding/json"
+	applemusiclib "github.com/minchao/go-apple-music"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// applemusicHandler serves the part of the Apple Music API that spync uses
+type applemusicHandler struct {
+	catalog *catalog
+}
+
+func (h applemusicHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.catalog.mutex.Lock()
+	defer h.catalog.mutex.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	route := r.Method + " " + strings.Join(parts, "/")
+	switch {
+	case route == "GET v1/me/storefront":
+		writeJSON(w, http.StatusOK, applemusiclib.Storefronts{Data: []applemusiclib.Storefront{{Id: "nl", Type: "storefronts"}}})
+	case route == "GET v1/me/library/playlists":
+		h.getPlaylists(w, r)
+	case route == "POST v1/me/library/playlists":
+		h.createPlaylist(w, r)
+	case route == "POST v1/me/library":
+		h.addToLibrary(w, r)
+	case len(parts) == 5 && parts[3] == "playlists":
+		h.playlist(w, r, parts[4])
+	case len(parts) == 6 && parts[3] == "playlists" && parts[5] == "tracks":
+		h.playlistTracks(w, r, parts[4])
+	case r.Method == http.MethodGet && len(parts) == 4 && parts[1] == "catalog" && parts[3] == "search":
+		h.search(w, r)
+	case r.Method == http.MethodGet && len(parts) == 4 && parts[1] == "catalog" && parts[3] == "songs":
+		h.getSongs(w, r)
+	case r.Method == http.MethodGet && len(parts) == 4 && parts[1] == "catalog" && parts[3] == "albums":
+		h.getAlbums(w, r)
+	default:
+		writeApplemusicError(w, http.StatusNotFound, "Resource Not Found")
+	}
+}
+
+func (h applemusicHandler) catalogSong(songIndex int) applemusiclib.Song {
+	s := h.catalog.songs[songIndex]
+	contentRating := ""
+	if s.explicit {
+		contentRating = "explicit"
+	}
+	return applemusiclib.Song{
+		Id:   s.applemusicId,
+		Type: "songs",
+		Attributes: applemusiclib.SongAttributes{
+			Name:             s.name,
+			ArtistName:       s.artist,
+			AlbumName:        s.album,
+			ISRC:             s.isrc,
+			DurationInMillis: int64(s.durationMs),
+			ContentRating:    contentRating,
+			PlayParams:       &applemusiclib.PlayParameters{Id: s.applemusicId, Kind: "song"},
+		},
+	}
+}
+
+func (h applemusicHandler) catalogAlbum(a album) applemusiclib.Album {
+	return applemusiclib.Album{
+		Id:         a.applemusicId,
+		Type:       "albums",
+		Attributes: applemusiclib.AlbumAttributes{Name: a.name, ArtistName: a.artist},
+	}
+}
+
+func (h applemusicHandler) libraryPlaylist(playlist *libraryPlaylist) applemusiclib.LibraryPlaylist {
+	return applemusiclib.LibraryPlaylist{
+		Id:   playlist.id,
+		Type: "library-playlists",
+		Attributes: applemusiclib.LibraryPlaylistAttributes{
+			Name:        playlist.name,
+			Description: &applemusiclib.EditorialNotes{Standard: playlist.description},
+			CanEdit:     true,
+			CanDelete:   true,
+		},
+	}
+}
+
+func (h applemusicHandler) getPlaylists(w http.ResponseWriter, r *http.Request) {
+	playlists := make([]applemusiclib.LibraryPlaylist, 0)
+	for _, playlist := range h.catalog.libraryPlaylists {
+		playlists = append(playlists, h.libraryPlaylist(playlist))
+	}
+
+	start, end := getPage(r, len(playlists))
+	writeJSON(w, http.StatusOK, applemusiclib.LibraryPlaylists{Data: playlists[start:end]})
+}
+
+func (h applemusicHandler) createPlaylist(w http.ResponseWriter, r *http.Request) {
+	var body applemusiclib.CreateLibraryPlaylist
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	playlist := &libraryPlaylist{
+		id:          h.catalog.newId("p.sandbox"),
+		name:        body.Attributes.Name,
+		description: body.Attributes.Description,
+		songIds:     make([]string, 0),
+	}
+	h.catalog.libraryPlaylists = append(h.catalog.libraryPlaylists, playlist)
+	writeJSON(w, http.StatusCreated, applemusiclib.LibraryPlaylists{Data: []applemusiclib.LibraryPlaylist{h.libraryPlaylist(playlist)}})
+}
+
+func (h applemusicHandler) playlist(w http.ResponseWriter, r *http.Request, playlistId string) {
+	playlist := h.catalog.findLibraryPlaylist(playlistId)
+	if playlist == nil {
+		writeApplemusicError(w, http.StatusNotFound, "Resource Not Found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, applemusiclib.LibraryPlaylists{Data: []applemusiclib.LibraryPlaylist{h.libraryPlaylist(playlist)}})
+	case http.MethodDelete:
+		playlists := make([]*libraryPlaylist, 0)
+		for _, libraryPlaylist := range h.catalog.libraryPlaylists {
+			if libraryPlaylist != playlist {
+				playlists = append(playlists, libraryPlaylist)
+			}
+		}
+		h.catalog.libraryPlaylists = playlists
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeApplemusicError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// playlistTracks uses "i." and the catalog id as the library id of a song
+func (h applemusicHandler) playlistTracks(w http.ResponseWriter, r *http.Request, playlistId string) {
+	playlist := h.catalog.findLibraryPlaylist(playlistId)
+	if playlist == nil {
+		writeApplemusicError(w, http.StatusNotFound, "Resource Not Found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		// Like the real API, an empty playlist has no tracks resource
+		if len(playlist.songIds) == 0 {
+			writeApplemusicError(w, http.StatusNotFound, "Resource Not Found")
+			return
+		}
+
+		songs := make([]applemusiclib.Song, 0)
+		for _, songId := range playlist.songIds {
+			songIndex, _ := h.catalog.findApplemusicSong(songId)
+			song := h.catalogSong(songIndex)
+			song.Id = "i." + songId
+			song.Type = "library-songs"
+			song.Attributes.PlayParams = &applemusiclib.PlayParameters{Id: song.Id, Kind: "song", IsLibrary: true, CatalogId: songId}
+			songs = append(songs, song)
+		}
+		writeJSON(w, http.StatusOK, applemusiclib.Songs{Data: songs})
+	case http.MethodPost:
+		var body applemusiclib.CreateLibraryPlaylistTrackData
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		for _, track := range body.Data {
+			if _, ok := h.catalog.findApplemusicSong(track.Id); ok {
+				playlist.songIds = append(playlist.songIds, track.Id)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		remove := make(map[string]bool)
+		for _, libraryId := range strings.Split(r.URL.Query().Get("ids[library-songs]"), ",") {
+			remove[strings.TrimPrefix(libraryId, "i.")] = true
+		}
+		songIds := make([]string, 0)
+		for _, songId := range playlist.songIds {
+			if !remove[songId] {
+				songIds = append(songIds, songId)
+			}
+		}
+		playlist.songIds = songIds
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeApplemusicError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+func (h applemusicHandler) addToLibrary(w http.ResponseWriter, r *http.Request) {
+	for _, songId := range strings.Split(r.URL.Query().Get("ids[songs]"), ",") {
+		if songId != "" {
+			h.catalog.librarySongIds[songId] = true
+		}
+	}
+	for _, albumId := range strings.Split(r.URL.Query().Get("ids[albums]"), ",") {
+		if albumId != "" {
+			h.catalog.libraryAlbumIds[albumId] = true
+		}
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// search matches songs and albums whose name and artist both appear in the term
+func (h applemusicHandler) search(w http.ResponseWriter, r *http.Request) {
+	term := strings.ToLower(r.URL.Query().Get("term"))
+	types := r.URL.Query().Get("types")
+
+	results := applemusiclib.SearchResults{}
+	if strings.Contains(types, "songs") {
+		songs := make([]applemusiclib.Song, 0)
+		for i, s := range h.catalog.songs {
+			name := strings.ToLower(s.name)
+			if index := strings.Index(name, " (feat."); index >= 0 {
+				name = name[:index]
+			}
+			if s.applemusicId != "" && strings.Contains(term, name) && strings.Contains(term, strings.ToLower(s.artist)) {
+				songs = append(songs, h.catalogSong(i))
+			}
+		}
+		if len(songs) > 0 {
+			results.Songs = &applemusiclib.Songs{Data: songs}
+		}
+	}
+	if strings.Contains(types, "albums") {
+		albums := make([]applemusiclib.Album, 0)
+		for _, a := range h.catalog.albums {
+			if strings.Contains(term, strings.ToLower(a.name)) && strings.Contains(term, strings.ToLower(a.artist)) {
+				albums = append(albums, h.catalogAlbum(a))
+			}
+		}
+		if len(albums) > 0 {
+			results.Albums = &applemusiclib.Albums{Data: albums}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, applemusiclib.Search{Results: results})
+}
+
+func (h applemusicHandler) getSongs(w http.ResponseWriter, r *http.Request) {
+	songs := make([]applemusiclib.Song, 0)
+	for _, songId := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		if songIndex, ok := h.catalog.findApplemusicSong(songId); ok {
+			songs = append(songs, h.catalogSong(songIndex))
+		}
+	}
+	for _, isrc := range strings.Split(r.URL.Query().Get("filter[isrc]"), ",") {
+		for i, s := range h.catalog.songs {
+			if isrc != "" && s.applemusicId != "" && s.isrc == isrc {
+				songs = append(songs, h.catalogSong(i))
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, applemusiclib.Songs{Data: songs})
+}
+
+func (h applemusicHandler) getAlbums(w http.ResponseWriter, r *http.Request) {
+	albums := make([]applemusiclib.Album, 0)
+	upc := r.URL.Query().Get("filter[upc]")
+	for _, a := range h.catalog.albums {
+		if upc != "" && a.upc == upc {
+			albums = append(albums, h.catalogAlbum(a))
+		}
+	}
+	writeJSON(w, http.StatusOK, applemusiclib.Albums{Data: albums})
+}
+
+func writeApplemusicError(w http.ResponseWriter, status int, title string) {
+	writeJSON(w, status, map[string]interface{}{"errors": []applemusiclib.Error{{Status: strconv.Itoa(status), Title: title}}})
+}
@@ -0,0 +1,210 @@
+package sandbox
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const userId = "sandbox-user"
+
+// song is a song of the sandbox catalog. Songs without a Spotify or an Apple Music id are missing in that service,
+// so match reports have something to show.
+type song struct {
+	spotifyId    string
+	applemusicId string
+	name         string
+	artist       string
+	album        string
+	isrc         string
+	durationMs   int
+	explicit     bool
+}
+
+type album struct {
+	spotifyId    string
+	applemusicId string
+	name         string
+	artist       string
+	upc          string
+}
+
+type playlistEntry struct {
+	// song is -1 for podcast episodes and -2 for tracks that are not available
+	song    int
+	addedAt time.Time
+}
+
+type spotifyPlaylist struct {
+	id            string
+	name          string
+	description   string
+	owner         string
+	collaborative bool
+	entries       []playlistEntry
+	snapshot      int
+}
+
+type libraryPlaylist struct {
+	id          string
+	name        string
+	description string
+	songIds     []string
+}
+
+// catalog holds the data of both fake services. It is changed by the requests of spync, so syncs behave like they
+// would against the real services.
+type catalog struct {
+	mutex sync.Mutex
+
+	songs            []song
+	albums           []album
+	spotifyPlaylists []*spotifyPlaylist
+	savedTracks      []playlistEntry
+	savedAlbums      []int
+	savedAlbumsDate  time.Time
+	recentlyPlayed   []playlistEntry
+
+	libraryPlaylists []*libraryPlaylist
+	librarySongIds   map[string]bool
+	libraryAlbumIds  map[string]bool
+
+	nextId int
+}
+
+func sandboxId(kind string, n int) string {
+	// Spotify ids are 22 characters long
+	return fmt.Sprintf("sandbox%s%0*d", kind, 15-len(kind), n)
+}
+
+func newCatalog() *catalog {
+	c := &catalog{
+		librarySongIds:  make(map[string]bool),
+		libraryAlbumIds: make(map[string]bool),
+		nextId:          1,
+	}
+
+	seedSongs := []struct {
+		name, artist, album string
+		durationMs          int
+		explicit            bool
+		spotify, applemusic bool
+	}{
+		{"Golden Hour", "The Sandbox Band", "Daylight", 214000, false, true, true},
+		{"Night Drive", "The Sandbox Band", "Daylight", 243000, false, true, true},
+		{"Paper Planes", "Mock Orchestra", "Fixtures", 198000, false, true, true},
+		{"Offline (feat. Stub)", "Mock Orchestra", "Fixtures", 187000, true, true, true},
+		{"Interlude", "Mock Orchestra", "Fixtures", 41000, false, true, true},
+		{"Localhost", "Loopback", "127", 225000, false, true, true},
+		{"Ping", "Loopback", "127", 176000, false, true, true},
+		{"Timeout", "Loopback", "127", 301000, true, true, true},
+		{"Green Tests", "The Assertions", "Coverage", 205000, false, true, true},
+		{"Flaky", "The Assertions", "Coverage", 232000, false, true, false},
+		{"Refactor", "The Assertions", "Coverage", 219000, false, true, true},
+		{"Merge Conflict", "Rebase", "History", 264000, true, true, true},
+		{"Fast Forward", "Rebase", "History", 189000, false, true, true},
+		{"Detached Head", "Rebase", "History", 247000, false, true, false},
+		{"Cold Start", "Cache Miss", "Warm Up", 211000, false, true, true},
+		{"Hot Reload", "Cache Miss", "Warm Up", 196000, false, true, true},
+		{"Eviction", "Cache Miss", "Warm Up", 228000, false, true, true},
+		{"Sunday Morning Stub", "Apple Exclusive", "Library Only", 252000, false, false, true},
+		{"Lossless", "Apple Exclusive", "Library Only", 239000, false, false, true},
+	}
+
+	albumIndexes := make(map[string]int)
+	for i, seedSong := range seedSongs {
+		s := song{
+			name:       seedSong.name,
+			artist:     seedSong.artist,
+			album:      seedSong.album,
+			isrc:       fmt.Sprintf("SBX%09d", i+1),
+			durationMs: seedSong.durationMs,
+			explicit:   seedSong.explicit,
+		}
+		if seedSong.spotify {
+			s.spotifyId = sandboxId("track", i+1)
+		}
+		if seedSong.applemusic {
+			s.applemusicId = strconv.Itoa(1500000000 + i + 1)
+		}
+		c.songs = append(c.songs, s)
+
+		if _, exists := albumIndexes[seedSong.album]; !exists {
+			albumIndexes[seedSong.album] = len(c.albums)
+			c.albums = append(c.albums, album{
+				spotifyId:    sandboxId("album", len(c.albums)+1),
+				applemusicId: strconv.Itoa(1400000000 + len(c.albums) + 1),
+				name:         seedSong.album,
+				artist:       seedSong.artist,
+				upc:          fmt.Sprintf("%012d", 880000000000+len(c.albums)+1),
+			})
+		}
+	}
+
+	now := time.Now()
+	entries := func(songs ...int) []playlistEntry {
+		items := make([]playlistEntry, 0)
+		for i, songIndex := range songs {
+			items = append(items, playlistEntry{song: songIndex, addedAt: now.AddDate(0, 0, -30+i)})
+		}
+		return items
+	}
+
+	c.spotifyPlaylists = []*spotifyPlaylist{
+		{id: sandboxId("playlist", 1), name: "Road Trip", owner: userId, entries: entries(0, 1, 5, 6, 7, 11, 12, 9)},
+		{id: sandboxId("playlist", 2), name: "Focus", owner: userId, collaborative: true, entries: entries(2, 4, 8, 10, 13, 14)},
+		{id: sandboxId("playlist", 3), name: "Discover Weekly", owner: "spotify", entries: entries(3, 15, 16, -1, -2, 9)},
+	}
+	c.savedTracks = entries(0, 2, 6, 8, 12, 14)
+	c.savedAlbums = []int{0, 2, 4}
+	c.savedAlbumsDate = now.AddDate(0, 0, -30)
+	c.recentlyPlayed = entries(16, 1, 3, 1, 11)
+
+	c.libraryPlaylists = []*libraryPlaylist{
+		{id: "p.sandbox1", name: "Sunday Morning", songIds: []string{c.songs[17].applemusicId, c.songs[0].applemusicId, c.songs[18].applemusicId}},
+	}
+
+	return c
+}
+
+func (c *catalog) newId(prefix string) string {
+	c.nextId++
+	return fmt.Sprintf("%s%d", prefix, c.nextId)
+}
+
+func (c *catalog) findSpotifySong(spotifyId string) (int, bool) {
+	for i, s := range c.songs {
+		if s.spotifyId != "" && s.spotifyId == spotifyId {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (c *catalog) findApplemusicSong(applemusicId string) (int, bool) {
+	for i, s := range c.songs {
+		if s.applemusicId != "" && s.applemusicId == applemusicId {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (c *catalog) findSpotifyPlaylist(playlistId string) *spotifyPlaylist {
+	for _, playlist := range c.spotifyPlaylists {
+		if playlist.id == playlistId {
+			return playlist
+		}
+	}
+	return nil
+}
+
+func (c *catalog) findLibraryPlaylist(playlistId string) *libraryPlaylist {
+	for _, playlist := range c.libraryPlaylists {
+		if playlist.id == playlistId {
+			return playlist
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package sandbox
+
+import (
+	"api/internal/applemusic"
+	"api/internal/configuration"
+	"api/internal/spotify"
+	"api/internal/state"
+	"api/internal/storage"
+	"go.uber.org/zap"
+	"os"
+	"time"
+)
+
+// directory keeps the configuration and state of the sandbox apart from the real ones
+const directory = "sandbox"
+
+// IsEnabled reports whether spync runs against the sandbox instead of the real services
+func IsEnabled() bool {
+	return os.Getenv("SPYNC_SANDBOX") == "true"
+}
+
+// Start serves fake Spotify and Apple Music backends with seeded data and points both clients at them. Nothing
+// leaves the machine, and the data is reset every time the API starts.
+func Start() error {
+	logger := getLogger()
+	err := UseDirectory()
+	if err != nil {
+		return err
+	}
+
+	c := newCatalog()
+	spotifyURL, err := serve(spotifyHandler{catalog: c})
+	if err != nil {
+		return err
+	}
+	applemusicURL, err := serve(applemusicHandler{catalog: c})
+	if err != nil {
+		return err
+	}
+
+	spotify.SetBaseURL(spotifyURL)
+	err = applemusic.SetBaseURL(applemusicURL)
+	if err != nil {
+		return err
+	}
+	logger.Info("sandbox Spotify API: ", spotifyURL, ", sandbox Apple Music API: ", applemusicURL)
+
+	return seed(c)
+}
+
+// UseDirectory makes the configuration and state be read from the sandbox directory
+func UseDirectory() error {
+	err := os.MkdirAll(directory, 0755)
+	if err != nil {
+		return err
+	}
+	storage.SetDirectory(directory)
+	return nil
+}
+
+// seed writes a configuration that uses every kind of source, and tokens so both services count as connected
+func seed(c *catalog) error {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return err
+	}
+
+	if config.AppleMusic.DeveloperToken == "" {
+		config.SyncInterval = 60
+		config.AppleMusic.DeveloperToken = "sandbox"
+		config.AppleMusic.PlaylistIds = []string{c.libraryPlaylists[0].id}
+		config.Spotify.SyncSavedAlbums = true
+		config.Spotify.Sources = []string{spotify.LikedSongsId, spotify.TopTracksShortTermId, spotify.RecentlyPlayedId}
+		config.Playlists = make(map[string]configuration.PlaylistConfig)
+		for _, playlist := range c.spotifyPlaylists {
+			config.Spotify.PlaylistIds = append(config.Spotify.PlaylistIds, playlist.id)
+			config.Playlists[playlist.id] = configuration.PlaylistConfig{Name: playlist.name, Owner: playlist.owner}
+		}
+
+		// Show the filters and the archive mode
+		focus := config.Playlists[c.spotifyPlaylists[1].id]
+		focus.Filters.ExcludeExplicit = true
+		config.Playlists[c.spotifyPlaylists[1].id] = focus
+		discoverWeekly := config.Playlists[c.spotifyPlaylists[2].id]
+		discoverWeekly.Mode = configuration.ModeArchive
+		discoverWeekly.Archive.AllTime = true
+		config.Playlists[c.spotifyPlaylists[2].id] = discoverWeekly
+
+		err = configuration.SaveConfiguration(config, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The backends start empty, so the state of a previous run no longer applies
+	stateObj := state.State{}
+	stateObj.AppleMusic.AccessToken = "sandbox"
+	stateObj.Spotify.AccessToken = "sandbox"
+	stateObj.Spotify.TokenType = "Bearer"
+	stateObj.Spotify.Expiry = time.Now().AddDate(10, 0, 0)
+	return state.SaveState(stateObj)
+}
+
+func getLogger() *zap.SugaredLogger {
+	logger, _ := zap.NewDevelopment()
+	sugar := logger.Sugar()
+	defer func(logger *zap.Logger) {
+		_ = logger.Sync()
+	}(logger)
+	return sugar
+}
@@ -0,0 +1,319 @@
+package sandbox
+
+import (
+	"encoding/json"
+	spotifylib "github.com/zmb3/spotify/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// spotifyHandler serves the part of the Spotify Web API that spync uses
+type spotifyHandler struct {
+	catalog *catalog
+}
+
+func (h spotifyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.catalog.mutex.Lock()
+	defer h.catalog.mutex.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	route := r.Method + " " + strings.Join(parts, "/")
+	switch {
+	case route == "GET me":
+		writeJSON(w, http.StatusOK, spotifylib.PrivateUser{User: h.user()})
+	case route == "GET me/playlists":
+		h.getPlaylists(w, r)
+	case route == "GET me/tracks":
+		h.getSavedTracks(w, r)
+	case route == "GET me/albums":
+		h.getSavedAlbums(w, r)
+	case route == "GET me/top/tracks":
+		h.getTopTracks(w, r)
+	case route == "GET me/player/recently-played":
+		h.getRecentlyPlayed(w)
+	case route == "GET tracks":
+		h.getTracks(w, r)
+	case route == "GET search":
+		h.search(w, r)
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "tracks":
+		h.getTrack(w, parts[1])
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "playlists":
+		h.getPlaylist(w, parts[1])
+	case len(parts) == 3 && parts[0] == "playlists" && parts[2] == "tracks":
+		h.playlistTracks(w, r, parts[1])
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "users" && parts[2] == "playlists":
+		h.createPlaylist(w, r)
+	default:
+		writeSpotifyError(w, http.StatusNotFound, "Service not found")
+	}
+}
+
+func (h spotifyHandler) user() spotifylib.User {
+	return spotifylib.User{ID: userId, DisplayName: "Sandbox User"}
+}
+
+func (h spotifyHandler) fullTrack(songIndex int) spotifylib.FullTrack {
+	s := h.catalog.songs[songIndex]
+	albumId := ""
+	for _, a := range h.catalog.albums {
+		if a.name == s.album {
+			albumId = a.spotifyId
+		}
+	}
+
+	track := spotifylib.FullTrack{
+		Album: spotifylib.SimpleAlbum{
+			ID:      spotifylib.ID(albumId),
+			Name:    s.album,
+			Artists: []spotifylib.SimpleArtist{{Name: s.artist}},
+		},
+		ExternalIDs: map[string]string{"isrc": s.isrc},
+	}
+	track.ID = spotifylib.ID(s.spotifyId)
+	track.URI = spotifylib.URI("spotify:track:" + s.spotifyId)
+	track.Name = s.name
+	track.Type = "track"
+	track.Artists = []spotifylib.SimpleArtist{{Name: s.artist}}
+	track.Duration = s.durationMs
+	track.Explicit = s.explicit
+	return track
+}
+
+func (h spotifyHandler) simplePlaylist(playlist *spotifyPlaylist) spotifylib.SimplePlaylist {
+	owner := h.user()
+	if playlist.owner != userId {
+		owner = spotifylib.User{ID: playlist.owner, DisplayName: "Spotify"}
+	}
+	return spotifylib.SimplePlaylist{
+		ID:            spotifylib.ID(playlist.id),
+		Name:          playlist.name,
+		Description:   playlist.description,
+		Owner:         owner,
+		Collaborative: playlist.collaborative,
+		SnapshotID:    strconv.Itoa(playlist.snapshot),
+		Tracks:        spotifylib.PlaylistTracks{Total: uint(len(playlist.entries))},
+	}
+}
+
+func (h spotifyHandler) getPlaylists(w http.ResponseWriter, r *http.Request) {
+	playlists := make([]spotifylib.SimplePlaylist, 0)
+	for _, playlist := range h.catalog.spotifyPlaylists {
+		playlists = append(playlists, h.simplePlaylist(playlist))
+	}
+
+	start, end := getPage(r, len(playlists))
+	page := spotifylib.SimplePlaylistPage{Playlists: playlists[start:end]}
+	page.Total = len(playlists)
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (h spotifyHandler) getPlaylist(w http.ResponseWriter, playlistId string) {
+	playlist := h.catalog.findSpotifyPlaylist(playlistId)
+	if playlist == nil {
+		writeSpotifyError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, spotifylib.FullPlaylist{SimplePlaylist: h.simplePlaylist(playlist)})
+}
+
+func (h spotifyHandler) playlistTracks(w http.ResponseWriter, r *http.Request, playlistId string) {
+	playlist := h.catalog.findSpotifyPlaylist(playlistId)
+	if playlist == nil {
+		writeSpotifyError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		items := make([]map[string]interface{}, 0)
+		for _, entry := range playlist.entries {
+			item := map[string]interface{}{"added_at": entry.addedAt.Format(spotifylib.TimestampLayout), "is_local": false}
+			switch entry.song {
+			case -1:
+				item["track"] = map[string]interface{}{"type": "episode", "id": sandboxId("episode", 1), "name": "Sandbox Podcast #1"}
+			case -2:
+				item["track"] = nil
+			default:
+				item["track"] = h.fullTrack(entry.song)
+			}
+			items = append(items, item)
+		}
+
+		start, end := getPage(r, len(items))
+		writeJSON(w, http.StatusOK, map[string]interface{}{"items": items[start:end], "total": len(items)})
+	case http.MethodPost:
+		var body struct {
+			Uris []string `json:"uris"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		for _, uri := range body.Uris {
+			if songIndex, ok := h.catalog.findSpotifySong(strings.TrimPrefix(uri, "spotify:track:")); ok {
+				playlist.entries = append(playlist.entries, playlistEntry{song: songIndex, addedAt: time.Now()})
+			}
+		}
+		playlist.snapshot++
+		writeJSON(w, http.StatusCreated, map[string]string{"snapshot_id": strconv.Itoa(playlist.snapshot)})
+	case http.MethodDelete:
+		var body struct {
+			Tracks []struct {
+				URI string `json:"uri"`
+			} `json:"tracks"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		remove := make(map[string]bool)
+		for _, track := range body.Tracks {
+			remove[strings.TrimPrefix(track.URI, "spotify:track:")] = true
+		}
+		entries := make([]playlistEntry, 0)
+		for _, entry := range playlist.entries {
+			if entry.song < 0 || !remove[h.catalog.songs[entry.song].spotifyId] {
+				entries = append(entries, entry)
+			}
+		}
+		playlist.entries = entries
+		playlist.snapshot++
+		writeJSON(w, http.StatusOK, map[string]string{"snapshot_id": strconv.Itoa(playlist.snapshot)})
+	default:
+		writeSpotifyError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (h spotifyHandler) createPlaylist(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	playlist := &spotifyPlaylist{
+		id:          sandboxId("playlist", 100+len(h.catalog.spotifyPlaylists)),
+		name:        body.Name,
+		description: body.Description,
+		owner:       userId,
+		entries:     make([]playlistEntry, 0),
+	}
+	h.catalog.spotifyPlaylists = append(h.catalog.spotifyPlaylists, playlist)
+	writeJSON(w, http.StatusCreated, spotifylib.FullPlaylist{SimplePlaylist: h.simplePlaylist(playlist)})
+}
+
+func (h spotifyHandler) getSavedTracks(w http.ResponseWriter, r *http.Request) {
+	tracks := make([]spotifylib.SavedTrack, 0)
+	for _, entry := range h.catalog.savedTracks {
+		tracks = append(tracks, spotifylib.SavedTrack{
+			AddedAt:   entry.addedAt.Format(spotifylib.TimestampLayout),
+			FullTrack: h.fullTrack(entry.song),
+		})
+	}
+
+	start, end := getPage(r, len(tracks))
+	page := spotifylib.SavedTrackPage{Tracks: tracks[start:end]}
+	page.Total = len(tracks)
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (h spotifyHandler) getSavedAlbums(w http.ResponseWriter, r *http.Request) {
+	albums := make([]spotifylib.SavedAlbum, 0)
+	for _, albumIndex := range h.catalog.savedAlbums {
+		a := h.catalog.albums[albumIndex]
+		savedAlbum := spotifylib.SavedAlbum{AddedAt: h.catalog.savedAlbumsDate.Format(spotifylib.TimestampLayout)}
+		savedAlbum.ID = spotifylib.ID(a.spotifyId)
+		savedAlbum.Name = a.name
+		savedAlbum.Artists = []spotifylib.SimpleArtist{{Name: a.artist}}
+		savedAlbum.ExternalIDs = map[string]string{"upc": a.upc}
+		albums = append(albums, savedAlbum)
+	}
+
+	start, end := getPage(r, len(albums))
+	page := spotifylib.SavedAlbumPage{Albums: albums[start:end]}
+	page.Total = len(albums)
+	writeJSON(w, http.StatusOK, page)
+}
+
+// getTopTracks orders the songs differently for every time range, so each range gets its own playlist
+func (h spotifyHandler) getTopTracks(w http.ResponseWriter, r *http.Request) {
+	step := map[string]int{"short_term": 1, "medium_term": 3, "long_term": 5}[r.URL.Query().Get("time_range")]
+	if step == 0 {
+		step = 3
+	}
+
+	tracks := make([]spotifylib.FullTrack, 0)
+	for i := range h.catalog.songs {
+		songIndex := (i * step) % len(h.catalog.songs)
+		if h.catalog.songs[songIndex].spotifyId != "" && len(tracks) < 10 {
+			tracks = append(tracks, h.fullTrack(songIndex))
+		}
+	}
+
+	start, end := getPage(r, len(tracks))
+	page := spotifylib.FullTrackPage{Tracks: tracks[start:end]}
+	page.Total = len(tracks)
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (h spotifyHandler) getRecentlyPlayed(w http.ResponseWriter) {
+	items := make([]spotifylib.RecentlyPlayedItem, 0)
+	for _, entry := range h.catalog.recentlyPlayed {
+		items = append(items, spotifylib.RecentlyPlayedItem{
+			Track:    h.fullTrack(entry.song).SimpleTrack,
+			PlayedAt: entry.addedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, spotifylib.RecentlyPlayedResult{Items: items})
+}
+
+func (h spotifyHandler) getTrack(w http.ResponseWriter, trackId string) {
+	songIndex, ok := h.catalog.findSpotifySong(trackId)
+	if !ok {
+		writeSpotifyError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+	writeJSON(w, http.StatusOK, h.fullTrack(songIndex))
+}
+
+func (h spotifyHandler) getTracks(w http.ResponseWriter, r *http.Request) {
+	tracks := make([]*spotifylib.FullTrack, 0)
+	for _, trackId := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		if songIndex, ok := h.catalog.findSpotifySong(trackId); ok {
+			track := h.fullTrack(songIndex)
+			tracks = append(tracks, &track)
+		} else {
+			tracks = append(tracks, nil)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tracks": tracks})
+}
+
+// search supports the "isrc:" and "track: artist:" queries spync sends, the fields may be quoted
+func (h spotifyHandler) search(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(r.URL.Query().Get("q"))
+	tracks := make([]spotifylib.FullTrack, 0)
+	for i, s := range h.catalog.songs {
+		if s.spotifyId == "" {
+			continue
+		}
+
+		var matches bool
+		if strings.HasPrefix(query, "isrc:") {
+			matches = strings.TrimPrefix(query, "isrc:") == strings.ToLower(s.isrc)
+		} else {
+			name, artist, _ := strings.Cut(strings.TrimPrefix(query, "track:"), " artist:")
+			matches = strings.Contains(strings.ToLower(s.name), strings.Trim(name, `" `)) &&
+				strings.Contains(strings.ToLower(s.artist), strings.Trim(artist, `" `))
+		}
+		if matches {
+			tracks = append(tracks, h.fullTrack(i))
+		}
+	}
+
+	start, end := getPage(r, len(tracks))
+	page := spotifylib.FullTrackPage{Tracks: tracks[start:end]}
+	page.Total = len(tracks)
+	writeJSON(w, http.StatusOK, spotifylib.SearchResult{Tracks: &page})
+}
+
+func writeSpotifyError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"error": map[string]interface{}{"status": status, "message": message}})
+}
@@ -0,0 +1,32 @@
+package spotify
+
+import (
+	"context"
+	spotifylib "github.com/zmb3/spotify/v2"
+)
+
+// SavedAlbumsId identifies the saved albums of the user in sync results
+const SavedAlbumsId = "saved-albums"
+
+func GetSavedAlbums() ([]spotifylib.SavedAlbum, error) {
+	client := getSpotifyClient()
+	total := 1
+	offset := 0
+	items := make([]spotifylib.SavedAlbum, 0)
+	for total != len(items) {
+		albumsPage, err := client.CurrentUsersAlbums(context.TODO(),
+			spotifylib.Limit(50),
+			spotifylib.Offset(offset))
+		if err != nil {
+			return nil, err
+		}
+		if len(albumsPage.Albums) == 0 {
+			break
+		}
+
+		items = append(items, albumsPage.Albums...)
+		total = albumsPage.Total
+		offset += 50
+	}
+	return items, nil
+}
@@ -12,11 +12,26 @@ import (
 	"net/http"
 )
 
+var baseURL = ""
+
+// SetBaseURL sends every request to another Web API, which is used by the sandbox
+func SetBaseURL(url string) {
+	baseURL = url
+}
+
 func getAuthenticator() *spotifyauth.Authenticator {
 	config, _ := configuration.GetConfiguration()
 	return spotifyauth.New(
 		spotifyauth.WithRedirectURL(config.Spotify.RedirectUrl),
-		spotifyauth.WithScopes(spotifyauth.ScopePlaylistReadPrivate, spotifyauth.ScopePlaylistReadCollaborative),
+		spotifyauth.WithScopes(
+			spotifyauth.ScopePlaylistReadPrivate,
+			spotifyauth.ScopePlaylistReadCollaborative,
+			spotifyauth.ScopeUserLibraryRead,
+			spotifyauth.ScopeUserTopRead,
+			spotifyauth.ScopeUserReadRecentlyPlayed,
+			spotifyauth.ScopePlaylistModifyPrivate,
+			spotifyauth.ScopePlaylistModifyPublic,
+		),
 		spotifyauth.WithClientID(config.Spotify.ClientId),
 		spotifyauth.WithClientSecret(config.Spotify.ClientSecret),
 	)
@@ -42,9 +57,13 @@ func SaveAuth(r *http.Request, stateParam string) error {
 }
 
 func GetPlaylist(playlistId string) (*spotifylib.FullPlaylist, error) {
+	if IsPseudoPlaylist(playlistId) {
+		return getPseudoPlaylist(playlistId)
+	}
+
 	playlist, err := getSpotifyClient().GetPlaylist(context.TODO(),
 		spotifylib.ID(playlistId),
-		spotifylib.Fields("images,id,name,owner"))
+		spotifylib.Fields("images,id,name,owner,snapshot_id"))
 	if err != nil {
 		return nil, err
 	}
@@ -72,7 +91,14 @@ func GetUserPlaylists() ([]spotifylib.SimplePlaylist, error) {
 	return items, nil
 }
 
+// marketFromToken makes Spotify report whether tracks are playable in the country of the user
+const marketFromToken = "from_token"
+
 func GetPlaylistTracks(playlistId string) ([]spotifylib.PlaylistItem, error) {
+	if IsPseudoPlaylist(playlistId) {
+		return getPseudoPlaylistTracks(playlistId)
+	}
+
 	client := getSpotifyClient()
 	total := 1
 	offset := 0
@@ -82,8 +108,9 @@ func GetPlaylistTracks(playlistId string) ([]spotifylib.PlaylistItem, error) {
 			spotifylib.ID(playlistId),
 			spotifylib.Limit(100),
 			spotifylib.Offset(offset),
-			spotifylib.Fields("items(added_at,track(album(artists(id,name),id,name,release_date,total_tracks),"+
-				"artists(id,name),duration_ms,external_ids,id,name,type)),total"))
+			spotifylib.Market(marketFromToken),
+			spotifylib.Fields("items(added_at,is_local,track(album(artists(id,name),id,name,release_date,total_tracks),"+
+				"artists(id,name),duration_ms,explicit,external_ids,id,is_playable,name,type,uri)),total"))
 		if err != nil {
 			return nil, err
 		}
@@ -124,6 +151,10 @@ func getSpotifyClient() *spotifylib.Client {
 		TokenType:    stateObj.Spotify.TokenType,
 		Expiry:       stateObj.Spotify.Expiry,
 	}
+	if baseURL != "" {
+		return spotifylib.New(http.DefaultClient, spotifylib.WithBaseURL(baseURL))
+	}
+
 	httpClient := getAuthenticator().Client(context.TODO(), &token)
 	return spotifylib.New(httpClient, spotifylib.WithRetry(true))
 }
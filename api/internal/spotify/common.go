@@ -12,3 +12,11 @@ func GetArtistNames(item *spotify.FullTrack) string {
 	}
 	return strings.Join(artistNames, " ")
 }
+
+func GetAlbumArtistNames(album *spotify.FullAlbum) string {
+	artistNames := make([]string, 0)
+	for _, artist := range album.Artists {
+		artistNames = append(artistNames, artist.Name)
+	}
+	return strings.Join(artistNames, " ")
+}
@@ -1,9 +1,14 @@
 package spotify
 
 import (
+	"api/internal/configuration"
 	"github.com/gin-gonic/gin"
 )
 
+type PlaylistLinkObject struct {
+	Playlist string `json:"playlist"`
+}
+
 func GetAuthURLEndpoint(c *gin.Context) {
 	c.Redirect(301, GetAuthUrl())
 }
@@ -30,6 +35,62 @@ func GetPlaylistsEndpoint(c *gin.Context) {
 	c.JSON(200, playlists)
 }
 
+func AddPlaylistEndpoint(c *gin.Context) {
+	var linkObj PlaylistLinkObject
+	decodeErr := c.BindJSON(&linkObj)
+	if decodeErr != nil {
+		c.JSON(400, gin.H{
+			"message": decodeErr.Error(),
+		})
+		return
+	}
+
+	playlistId, err := ParsePlaylistId(linkObj.Playlist)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Make sure the playlist exists and can be read before it gets synced
+	playlist, err := GetPlaylist(playlistId)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	owner := playlist.Owner.DisplayName
+	if owner == "" {
+		owner = playlist.Owner.ID
+	}
+
+	err = configuration.AddPlaylist(playlistId, playlist.Name, owner)
+	if err != nil {
+		c.JSON(500, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, playlist)
+}
+
+func RemovePlaylistEndpoint(c *gin.Context) {
+	playlistId := c.Param("playlistId")
+	err := configuration.RemovePlaylist(playlistId)
+	if err != nil {
+		c.JSON(500, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.Status(204)
+}
+
 func GetPlaylistTracksEndpoint(c *gin.Context) {
 	playlistId := c.Param("playlistId")
 	tracks, err := GetPlaylistTracks(playlistId)
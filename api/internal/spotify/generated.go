@@ -0,0 +1,138 @@
+package spotify
+
+import (
+	"context"
+	spotifylib "github.com/zmb3/spotify/v2"
+	"time"
+)
+
+const (
+	TopTracksShortTermId  = "top-tracks:short_term"
+	TopTracksMediumTermId = "top-tracks:medium_term"
+	TopTracksLongTermId   = "top-tracks:long_term"
+	RecentlyPlayedId      = "recently-played"
+)
+
+var generatedPlaylistNames = map[string]string{
+	TopTracksShortTermId:  "Top Tracks (last 4 weeks)",
+	TopTracksMediumTermId: "Top Tracks (last 6 months)",
+	TopTracksLongTermId:   "Top Tracks (all time)",
+	RecentlyPlayedId:      "Recently Played",
+}
+
+// IsGeneratedPlaylist reports whether the playlist is generated from the listening history of the user. These
+// playlists change completely over time.
+func IsGeneratedPlaylist(playlistId string) bool {
+	_, ok := generatedPlaylistNames[playlistId]
+	return ok
+}
+
+// IsPseudoPlaylist reports whether the id is a source of the user that is not a real playlist
+func IsPseudoPlaylist(playlistId string) bool {
+	return playlistId == LikedSongsId || IsGeneratedPlaylist(playlistId)
+}
+
+func getPseudoPlaylist(playlistId string) (*spotifylib.FullPlaylist, error) {
+	if playlistId == LikedSongsId {
+		return getLikedSongsPlaylist()
+	}
+
+	me, err := GetMe()
+	if err != nil {
+		return nil, err
+	}
+
+	return &spotifylib.FullPlaylist{
+		SimplePlaylist: spotifylib.SimplePlaylist{
+			ID:    spotifylib.ID(playlistId),
+			Name:  generatedPlaylistNames[playlistId],
+			Owner: me.User,
+		},
+	}, nil
+}
+
+func getPseudoPlaylistTracks(playlistId string) ([]spotifylib.PlaylistItem, error) {
+	switch playlistId {
+	case LikedSongsId:
+		return GetSavedTracks()
+	case TopTracksShortTermId:
+		return GetTopTracks(spotifylib.ShortTermRange)
+	case TopTracksMediumTermId:
+		return GetTopTracks(spotifylib.MediumTermRange)
+	case TopTracksLongTermId:
+		return GetTopTracks(spotifylib.LongTermRange)
+	default:
+		return GetRecentlyPlayed()
+	}
+}
+
+func GetTopTracks(timerange spotifylib.Range) ([]spotifylib.PlaylistItem, error) {
+	client := getSpotifyClient()
+	total := 1
+	offset := 0
+	items := make([]spotifylib.PlaylistItem, 0)
+	addedAt := time.Now().Format(time.RFC3339)
+	for total > len(items) {
+		tracksPage, err := client.CurrentUsersTopTracks(context.TODO(),
+			spotifylib.Timerange(timerange),
+			spotifylib.Limit(50),
+			spotifylib.Offset(offset))
+		if err != nil {
+			return nil, err
+		}
+		if len(tracksPage.Tracks) == 0 {
+			break
+		}
+
+		for i := range tracksPage.Tracks {
+			items = append(items, spotifylib.PlaylistItem{
+				AddedAt: addedAt,
+				Track:   spotifylib.PlaylistItemTrack{Track: &tracksPage.Tracks[i]},
+			})
+		}
+
+		total = tracksPage.Total
+		offset += 50
+	}
+	return items, nil
+}
+
+// GetRecentlyPlayed returns the last played tracks, most recent first. Tracks that were played more than once only
+// appear once.
+func GetRecentlyPlayed() ([]spotifylib.PlaylistItem, error) {
+	client := getSpotifyClient()
+	recentlyPlayed, err := client.PlayerRecentlyPlayedOpt(context.TODO(), &spotifylib.RecentlyPlayedOptions{Limit: 50})
+	if err != nil {
+		return nil, err
+	}
+
+	playedAt := make(map[spotifylib.ID]time.Time)
+	trackIds := make([]spotifylib.ID, 0)
+	for _, item := range recentlyPlayed {
+		if _, exists := playedAt[item.Track.ID]; !exists {
+			playedAt[item.Track.ID] = item.PlayedAt
+			trackIds = append(trackIds, item.Track.ID)
+		}
+	}
+
+	// Recently played tracks are simplified, the full tracks are needed for their ISRC
+	items := make([]spotifylib.PlaylistItem, 0)
+	if len(trackIds) == 0 {
+		return items, nil
+	}
+	tracks, err := client.GetTracks(context.TODO(), trackIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, track := range tracks {
+		if track == nil {
+			continue
+		}
+		items = append(items, spotifylib.PlaylistItem{
+			AddedAt: playedAt[track.ID].Format(time.RFC3339),
+			Track:   spotifylib.PlaylistItemTrack{Track: track},
+		})
+	}
+	return items, nil
+}
@@ -0,0 +1,86 @@
+package spotify
+
+import (
+	"api/internal/state"
+	spotifylib "github.com/zmb3/spotify/v2"
+	"strings"
+)
+
+const (
+	itemTrack       = "track"
+	itemLocal       = "local"
+	itemEpisode     = "episode"
+	itemUnavailable = "unavailable"
+)
+
+func classifyItem(item spotifylib.PlaylistItem) string {
+	track := item.Track.Track
+	if track == nil {
+		if item.Track.Episode != nil {
+			return itemEpisode
+		}
+		// Spotify returns no track at all when it is not available in the market of the user
+		return itemUnavailable
+	}
+	if item.IsLocal || IsLocalTrack(track) {
+		return itemLocal
+	}
+	if track.IsPlayable != nil && !*track.IsPlayable {
+		return itemUnavailable
+	}
+	return itemTrack
+}
+
+// IsLocalTrack reports whether the track is a local file of the user, which only exists in the Spotify app
+func IsLocalTrack(track *spotifylib.FullTrack) bool {
+	return strings.HasPrefix(string(track.URI), "spotify:local:")
+}
+
+// SkipItems removes the playlist items that can not be synced. Local files are kept when they have enough
+// metadata to search for them.
+func SkipItems(items []spotifylib.PlaylistItem) ([]spotifylib.PlaylistItem, []state.TrackReport) {
+	kept := make([]spotifylib.PlaylistItem, 0)
+	skipped := make([]state.TrackReport, 0)
+	for _, item := range items {
+		switch classifyItem(item) {
+		case itemEpisode:
+			episode := item.Track.Episode
+			skipped = append(skipped, state.TrackReport{TrackId: episode.ID.String(), Name: episode.Name, Reason: "podcast episode"})
+		case itemUnavailable:
+			if item.Track.Track == nil {
+				skipped = append(skipped, state.TrackReport{Reason: "not available on Spotify"})
+			} else {
+				skipped = append(skipped, newTrackReport(item.Track.Track, "not available on Spotify"))
+			}
+		case itemLocal:
+			track := item.Track.Track
+			if track.Name == "" || len(track.Artists) == 0 || track.Artists[0].Name == "" {
+				skipped = append(skipped, newTrackReport(track, "local file without artist or title"))
+				continue
+			}
+			// Local files have no id, the uri contains the metadata and is used to recognise them instead
+			if track.ID == "" {
+				track.ID = spotifylib.ID(track.URI)
+			}
+			kept = append(kept, item)
+		default:
+			track := item.Track.Track
+			if len(track.Artists) == 0 || track.Name == "" {
+				skipped = append(skipped, newTrackReport(track, "track without artist or title"))
+				continue
+			}
+			kept = append(kept, item)
+		}
+	}
+
+	return kept, skipped
+}
+
+func newTrackReport(track *spotifylib.FullTrack, reason string) state.TrackReport {
+	return state.TrackReport{
+		TrackId: track.ID.String(),
+		Name:    track.Name,
+		Artists: GetArtistNames(track),
+		Reason:  reason,
+	}
+}
@@ -0,0 +1,55 @@
+package spotify
+
+import (
+	"context"
+	spotifylib "github.com/zmb3/spotify/v2"
+)
+
+// LikedSongsId is the pseudo playlist id for the saved tracks of the user
+const LikedSongsId = "liked-songs"
+
+func GetSavedTracks() ([]spotifylib.PlaylistItem, error) {
+	client := getSpotifyClient()
+	total := 1
+	offset := 0
+	items := make([]spotifylib.PlaylistItem, 0)
+	for total != len(items) {
+		tracksPage, err := client.CurrentUsersTracks(context.TODO(),
+			spotifylib.Limit(50),
+			spotifylib.Offset(offset))
+		if err != nil {
+			return nil, err
+		}
+		if len(tracksPage.Tracks) == 0 {
+			break
+		}
+
+		// Saved tracks are turned into playlist items so they can be synced like any other playlist
+		for i := range tracksPage.Tracks {
+			savedTrack := tracksPage.Tracks[i]
+			items = append(items, spotifylib.PlaylistItem{
+				AddedAt: savedTrack.AddedAt,
+				Track:   spotifylib.PlaylistItemTrack{Track: &savedTrack.FullTrack},
+			})
+		}
+
+		total = tracksPage.Total
+		offset += 50
+	}
+	return items, nil
+}
+
+func getLikedSongsPlaylist() (*spotifylib.FullPlaylist, error) {
+	me, err := GetMe()
+	if err != nil {
+		return nil, err
+	}
+
+	return &spotifylib.FullPlaylist{
+		SimplePlaylist: spotifylib.SimplePlaylist{
+			ID:    LikedSongsId,
+			Name:  "Liked Songs",
+			Owner: me.User,
+		},
+	}, nil
+}
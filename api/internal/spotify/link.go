@@ -0,0 +1,47 @@
+package spotify
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var playlistIdRegex = regexp.MustCompile(`^[0-9A-Za-z]{22}$`)
+
+// ParsePlaylistId returns the playlist id from an open.spotify.com link, a spotify:playlist: URI or a raw id
+func ParsePlaylistId(input string) (string, error) {
+	input = strings.TrimSpace(input)
+
+	playlistId := input
+	if strings.HasPrefix(input, "spotify:") {
+		parts := strings.Split(input, ":")
+		if len(parts) < 3 || parts[len(parts)-2] != "playlist" {
+			return "", errors.New("not a Spotify playlist URI: " + input)
+		}
+		playlistId = parts[len(parts)-1]
+	} else if strings.Contains(input, "/") {
+		link, err := url.Parse(input)
+		if err != nil {
+			return "", err
+		}
+		if link.Host != "open.spotify.com" {
+			return "", errors.New("not a Spotify link: " + input)
+		}
+
+		// Links can be prefixed with a locale or /embed, the id always follows the playlist segment
+		segments := strings.Split(strings.Trim(link.Path, "/"), "/")
+		playlistId = ""
+		for i := 0; i < len(segments)-1; i++ {
+			if segments[i] == "playlist" {
+				playlistId = segments[i+1]
+			}
+		}
+	}
+
+	if !playlistIdRegex.MatchString(playlistId) {
+		return "", errors.New("not a Spotify playlist: " + input)
+	}
+
+	return playlistId, nil
+}
@@ -0,0 +1,41 @@
+package spotify
+
+import "testing"
+
+func TestParsePlaylistId(t *testing.T) {
+	const playlistId = "37i9dQZF1DXcBWIGoYBM5M"
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "raw id", input: playlistId, want: playlistId},
+		{name: "surrounding whitespace", input: "  " + playlistId + "\n", want: playlistId},
+		{name: "uri", input: "spotify:playlist:" + playlistId, want: playlistId},
+		{name: "user uri", input: "spotify:user:someone:playlist:" + playlistId, want: playlistId},
+		{name: "link", input: "https://open.spotify.com/playlist/" + playlistId, want: playlistId},
+		{name: "link with query", input: "https://open.spotify.com/playlist/" + playlistId + "?si=abc123", want: playlistId},
+		{name: "link with locale", input: "https://open.spotify.com/intl-nl/playlist/" + playlistId, want: playlistId},
+		{name: "embed link", input: "https://open.spotify.com/embed/playlist/" + playlistId, want: playlistId},
+		{name: "album uri", input: "spotify:album:" + playlistId, wantErr: true},
+		{name: "album link", input: "https://open.spotify.com/album/" + playlistId, wantErr: true},
+		{name: "other host", input: "https://example.com/playlist/" + playlistId, wantErr: true},
+		{name: "short id", input: "37i9dQZF1DX", wantErr: true},
+		{name: "invalid characters", input: "37i9dQZF1DXcBWIGoYBM5-", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParsePlaylistId(test.input)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParsePlaylistId(%q) error = %v, wantErr %v", test.input, err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("ParsePlaylistId(%q) = %q, want %q", test.input, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package spotify
+
+import (
+	"api/internal/provider"
+	"api/internal/state"
+	"errors"
+	spotifylib "github.com/zmb3/spotify/v2"
+	"strings"
+	"time"
+)
+
+// syncedDescription marks the playlists spync created on Spotify. Playlists that were synced from Apple Music by
+// earlier versions are marked with reverseDescription instead.
+const syncedDescription = "Synced with spync. (ID: "
+const reverseDescription = "Synced with spync. (Apple Music ID: "
+
+// Provider makes Spotify available as a source and as a destination
+type Provider struct{}
+
+// IsSyncedPlaylist reports whether spync created the playlist, these playlists are never synced back
+func IsSyncedPlaylist(description string) bool {
+	return strings.Contains(description, syncedDescription) || strings.HasPrefix(description, reverseDescription)
+}
+
+func ToTrack(track *spotifylib.FullTrack, addedAt string) provider.Track {
+	artists := make([]string, 0)
+	for _, artist := range track.Artists {
+		artists = append(artists, artist.Name)
+	}
+	addedAtTime, _ := time.Parse(spotifylib.TimestampLayout, addedAt)
+
+	return provider.Track{
+		Id:         track.ID.String(),
+		Name:       track.Name,
+		Artists:    artists,
+		Album:      track.Album.Name,
+		ISRC:       track.ExternalIDs["isrc"],
+		DurationMs: track.Duration,
+		Explicit:   track.Explicit,
+		AddedAt:    addedAtTime,
+		IsLocal:    IsLocalTrack(track),
+	}
+}
+
+func toPlaylist(playlist spotifylib.SimplePlaylist) provider.Playlist {
+	owner := playlist.Owner.DisplayName
+	if owner == "" {
+		owner = playlist.Owner.ID
+	}
+	return provider.Playlist{
+		Id:          playlist.ID.String(),
+		Name:        playlist.Name,
+		Owner:       owner,
+		Description: playlist.Description,
+		SnapshotId:  playlist.SnapshotID,
+	}
+}
+
+func (Provider) GetPlaylists() ([]provider.Playlist, error) {
+	playlists, err := GetUserPlaylists()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]provider.Playlist, 0)
+	for _, playlist := range playlists {
+		items = append(items, toPlaylist(playlist))
+	}
+	return items, nil
+}
+
+func (Provider) GetPlaylist(playlistId string) (*provider.Playlist, error) {
+	playlist, err := GetPlaylist(playlistId)
+	if err != nil {
+		return nil, err
+	}
+
+	item := toPlaylist(playlist.SimplePlaylist)
+	return &item, nil
+}
+
+func (Provider) GetPlaylistTracks(playlistId string) ([]provider.Track, []state.TrackReport, error) {
+	items, err := GetPlaylistTracks(playlistId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, skipped := SkipItems(items)
+	tracks := make([]provider.Track, 0)
+	for _, item := range items {
+		tracks = append(tracks, ToTrack(item.Track.Track, item.AddedAt))
+	}
+	return tracks, skipped, nil
+}
+
+func (Provider) FindTracks(tracks []provider.Track) ([]provider.MatchResult, error) {
+	results := make([]provider.MatchResult, 0)
+	for _, track := range tracks {
+		spotifyTrack, err := FindTrack(track)
+		if err != nil || spotifyTrack == nil {
+			results = append(results, provider.MatchResult{Err: err})
+		} else {
+			results = append(results, provider.MatchResult{TrackId: spotifyTrack.ID.String()})
+		}
+	}
+	return results, nil
+}
+
+func (p Provider) GetSyncedPlaylist(sourcePlaylistId string, _ string) (*provider.Playlist, error) {
+	playlists, err := p.GetPlaylists()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, playlist := range playlists {
+		if strings.Contains(playlist.Description, syncedDescription+sourcePlaylistId+")") ||
+			strings.HasPrefix(playlist.Description, reverseDescription+sourcePlaylistId+")") {
+			return &playlist, nil
+		}
+	}
+	return nil, nil
+}
+
+func (p Provider) CreatePlaylist(name string, sourcePlaylistId string) (*provider.Playlist, error) {
+	return p.CreateNamedPlaylist(name, name+". "+syncedDescription+sourcePlaylistId+")")
+}
+
+func (Provider) CreateNamedPlaylist(name string, description string) (*provider.Playlist, error) {
+	playlist, err := CreatePlaylist(name, description)
+	if err != nil {
+		return nil, err
+	}
+
+	item := toPlaylist(playlist.SimplePlaylist)
+	return &item, nil
+}
+
+func (Provider) GetPlaylistTrackIds(playlistId string) ([]string, error) {
+	items, err := GetPlaylistTracks(playlistId)
+	if err != nil {
+		return nil, err
+	}
+
+	trackIds := make([]string, 0)
+	for _, item := range items {
+		if item.Track.Track != nil {
+			trackIds = append(trackIds, item.Track.Track.ID.String())
+		}
+	}
+	return trackIds, nil
+}
+
+func (Provider) AddTracks(playlistId string, trackIds []string) error {
+	if IsPseudoPlaylist(playlistId) {
+		return errors.New("tracks can only be added to Spotify playlists")
+	}
+	return AddTracksToPlaylist(playlistId, trackIds)
+}
+
+func (Provider) RemoveTracks(playlistId string, trackIds []string) error {
+	if IsPseudoPlaylist(playlistId) {
+		return errors.New("tracks can only be removed from Spotify playlists")
+	}
+	for start := 0; start < len(trackIds); start += provider.MaxTracksPerRequest {
+		end := start + provider.MaxTracksPerRequest
+		if end > len(trackIds) {
+			end = len(trackIds)
+		}
+		err := RemoveTracksFromPlaylist(playlistId, trackIds[start:end])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (Provider) DeletePlaylist(playlistId string) error {
+	if IsPseudoPlaylist(playlistId) {
+		return errors.New("only Spotify playlists can be deleted")
+	}
+	return DeletePlaylist(playlistId)
+}
+
+func (Provider) GetSavedAlbums() ([]provider.Album, error) {
+	savedAlbums, err := GetSavedAlbums()
+	if err != nil {
+		return nil, err
+	}
+
+	albums := make([]provider.Album, 0)
+	for _, savedAlbum := range savedAlbums {
+		artists := make([]string, 0)
+		for _, artist := range savedAlbum.Artists {
+			artists = append(artists, artist.Name)
+		}
+		addedAt, _ := time.Parse(time.RFC3339, savedAlbum.AddedAt)
+		albums = append(albums, provider.Album{
+			Id:      savedAlbum.ID.String(),
+			Name:    savedAlbum.Name,
+			Artists: artists,
+			UPC:     savedAlbum.ExternalIDs["upc"],
+			AddedAt: addedAt,
+		})
+	}
+	return albums, nil
+}
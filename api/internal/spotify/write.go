@@ -0,0 +1,84 @@
+package spotify
+
+import (
+	"api/internal/provider"
+	"context"
+	spotifylib "github.com/zmb3/spotify/v2"
+	"strings"
+)
+
+// maxSearchResults is the amount of candidates that are compared with a track that is searched
+const maxSearchResults = 10
+
+// CreatePlaylist creates a private playlist for the user
+func CreatePlaylist(name string, description string) (*spotifylib.FullPlaylist, error) {
+	me, err := GetMe()
+	if err != nil {
+		return nil, err
+	}
+
+	return getSpotifyClient().CreatePlaylistForUser(context.TODO(), me.ID, name, description, false, false)
+}
+
+// DeletePlaylist unfollows the playlist, which is how Spotify deletes playlists of the user
+func DeletePlaylist(playlistId string) error {
+	return getSpotifyClient().UnfollowPlaylist(context.TODO(), spotifylib.ID(playlistId))
+}
+
+func AddTracksToPlaylist(playlistId string, trackIds []string) error {
+	_, err := getSpotifyClient().AddTracksToPlaylist(context.TODO(), spotifylib.ID(playlistId), toIds(trackIds)...)
+	return err
+}
+
+// RemoveTracksFromPlaylist removes every occurrence of the tracks from the playlist
+func RemoveTracksFromPlaylist(playlistId string, trackIds []string) error {
+	_, err := getSpotifyClient().RemoveTracksFromPlaylist(context.TODO(), spotifylib.ID(playlistId), toIds(trackIds)...)
+	return err
+}
+
+// FindTrack searches the Spotify catalog by ISRC first and by title and artist when that gives no result. Only a
+// candidate that is the same track is returned, the first result of a search is not always the right one.
+func FindTrack(track provider.Track) (*spotifylib.FullTrack, error) {
+	queries := make([]string, 0)
+	if track.ISRC != "" {
+		queries = append(queries, "isrc:"+track.ISRC)
+	}
+	query := "track:" + quoteSearchValue(provider.NormalizeTitle(track.Name))
+	if len(track.Artists) > 0 && track.Artists[0] != "" {
+		query += " artist:" + quoteSearchValue(track.Artists[0])
+	}
+	queries = append(queries, query)
+
+	client := getSpotifyClient()
+	for _, query := range queries {
+		result, err := client.Search(context.TODO(), query, spotifylib.SearchTypeTrack, spotifylib.Limit(maxSearchResults))
+		if err != nil {
+			return nil, err
+		}
+		if result.Tracks == nil {
+			continue
+		}
+
+		for i := range result.Tracks.Tracks {
+			candidate := &result.Tracks.Tracks[i]
+			if provider.IsSameTrack(track, ToTrack(candidate, "")) {
+				return candidate, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// quoteSearchValue quotes a field of a search query, so values with spaces or colons are searched as a whole
+func quoteSearchValue(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, "") + `"`
+}
+
+func toIds(trackIds []string) []spotifylib.ID {
+	ids := make([]spotifylib.ID, 0)
+	for _, trackId := range trackIds {
+		ids = append(ids, spotifylib.ID(trackId))
+	}
+	return ids
+}
@@ -16,12 +16,106 @@ type SpotifyState struct {
 	Expiry       time.Time `json:"expiry"`
 }
 
+// DeezerState is the login of the Deezer destination
+type DeezerState struct {
+	AccessToken string    `json:"access-token"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+// TidalState is the login of the Tidal destination
+type TidalState struct {
+	AccessToken  string    `json:"access-token"`
+	RefreshToken string    `json:"refresh-token"`
+	TokenType    string    `json:"token-type"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// YouTubeState is the login of the YouTube Music destination
+type YouTubeState struct {
+	AccessToken  string    `json:"access-token"`
+	RefreshToken string    `json:"refresh-token"`
+	TokenType    string    `json:"token-type"`
+	Expiry       time.Time `json:"expiry"`
+}
+
 type AppleMusicState struct {
-	AccessToken string `json:"access-token"`
+	AccessToken    string   `json:"access-token"`
+	LibrarySongIds []string `json:"library-song-ids"`
+}
+
+type TrackReport struct {
+	TrackId string `json:"track-id"`
+	Name    string `json:"name"`
+	Artists string `json:"artists"`
+	Reason  string `json:"reason"`
 }
 
+type ArchiveState struct {
+	PlaylistId  string    `json:"playlist-id"`
+	Name        string    `json:"name"`
+	SnapshotId  string    `json:"snapshot-id"`
+	CreatedDate time.Time `json:"created-date"`
+}
+
+// PlaylistState is the progress of a playlist that is synced into a destination. PlaylistId is the destination
+// playlist that was created for it, TrackIds caches the destination ids of source tracks and Mapping holds the tracks
+// that are on both sides of a two-way sync.
 type PlaylistState struct {
-	LastSyncDate time.Time `json:"last-sync-date"`
+	PlaylistId        string            `json:"playlist-id,omitempty"`
+	LastSyncDate      time.Time         `json:"last-sync-date"`
+	SkippedDuplicates int               `json:"skipped-duplicates"`
+	PendingTrackIds   []string          `json:"pending-track-ids"`
+	FailedTracks      []TrackReport     `json:"failed-tracks"`
+	UnmatchedTracks   []TrackReport     `json:"unmatched-tracks"`
+	FilteredTracks    []TrackReport     `json:"filtered-tracks"`
+	SkippedTracks     []TrackReport     `json:"skipped-tracks"`
+	TrackIds          map[string]string `json:"track-ids,omitempty"`
+	Mapping           map[string]string `json:"mapping,omitempty"`
+	DeletedDate       *time.Time        `json:"deleted-date,omitempty"`
+
+	LastSnapshotId    string         `json:"last-snapshot-id,omitempty"`
+	Archives          []ArchiveState `json:"archives,omitempty"`
+	AllTimePlaylistId string         `json:"all-time-playlist-id,omitempty"`
+}
+
+type AlbumReport struct {
+	AlbumId string `json:"album-id"`
+	Name    string `json:"name"`
+	Artists string `json:"artists"`
+	Reason  string `json:"reason"`
+}
+
+type AlbumsState struct {
+	LastSyncDate    time.Time     `json:"last-sync-date"`
+	PendingAlbumIds []string      `json:"pending-album-ids"`
+	FailedAlbums    []AlbumReport `json:"failed-albums"`
+	UnmatchedAlbums []AlbumReport `json:"unmatched-albums"`
+}
+
+// MergedTrack is a track of a target, together with the source playlists it came from. The destination id is empty
+// when the track could not be found.
+type MergedTrack struct {
+	TrackId       string   `json:"track-id"`
+	ISRC          string   `json:"isrc"`
+	DestinationId string   `json:"destination-id"`
+	SourceIds     []string `json:"source-ids"`
+}
+
+type TargetState struct {
+	PlaylistId      string        `json:"playlist-id"`
+	LastSyncDate    time.Time     `json:"last-sync-date"`
+	Tracks          []MergedTrack `json:"tracks"`
+	PendingTrackIds []string      `json:"pending-track-ids"`
+	FailedTracks    []TrackReport `json:"failed-tracks"`
+}
+
+// LocalPlaylistState is the playlist file a Spotify playlist was written to, with the tracks that are missing locally
+type LocalPlaylistState struct {
+	Path           string        `json:"path"`
+	LastSyncDate   time.Time     `json:"last-sync-date"`
+	MissingTracks  []TrackReport `json:"missing-tracks"`
+	SkippedTracks  []TrackReport `json:"skipped-tracks"`
+	FilteredTracks []TrackReport `json:"filtered-tracks"`
 }
 
 type State struct {
@@ -29,6 +123,16 @@ type State struct {
 	AppleMusic AppleMusicState          `json:"apple-music"`
 	Spotify    SpotifyState             `json:"spotify"`
 	Playlists  map[string]PlaylistState `json:"playlists"`
+	Albums     AlbumsState              `json:"albums"`
+	Targets    map[string]TargetState   `json:"targets"`
+
+	Pairs          map[string]PlaylistState      `json:"pairs"`
+	LocalPlaylists map[string]LocalPlaylistState `json:"local-playlists"`
+	Deezer         DeezerState                   `json:"deezer"`
+	Tidal          TidalState                    `json:"tidal"`
+	YouTube        YouTubeState                  `json:"youtube"`
+
+	SelectedPlaylistIds []string `json:"selected-playlist-ids"`
 }
 
 const stateFilePath = "state.json"
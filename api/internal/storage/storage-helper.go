@@ -4,9 +4,18 @@ import (
 	"errors"
 	"go.uber.org/zap"
 	"os"
+	"path"
 )
 
+var directory = ""
+
+// SetDirectory makes every file relative to the directory instead of the working directory
+func SetDirectory(dir string) {
+	directory = dir
+}
+
 func GetOrCreateFile(filepath string) (*os.File, bool, error) {
+	filepath = path.Join(directory, filepath)
 	logger, _ := zap.NewDevelopment()
 	sugar := logger.Sugar()
 	defer func(logger *zap.Logger) {
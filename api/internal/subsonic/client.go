@@ -0,0 +1,223 @@
+package subsonic
+
+import (
+	"api/internal/configuration"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const apiVersion = "1.16.1"
+const clientName = "spync"
+
+type Song struct {
+	Id       string `json:"id"`
+	Title    string `json:"title"`
+	Artist   string `json:"artist"`
+	Album    string `json:"album"`
+	Duration int    `json:"duration"`
+	// ISRC is only reported by OpenSubsonic servers, such as Navidrome
+	ISRC []string `json:"isrc"`
+}
+
+type Playlist struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Comment string `json:"comment"`
+	Entry   []Song `json:"entry"`
+}
+
+type response struct {
+	Status string `json:"status"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+	SearchResult3 struct {
+		Song []Song `json:"song"`
+	} `json:"searchResult3"`
+	Playlists struct {
+		Playlist []Playlist `json:"playlist"`
+	} `json:"playlists"`
+	Playlist Playlist `json:"playlist"`
+}
+
+func Search(query string) ([]Song, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("songCount", "20")
+	params.Set("artistCount", "0")
+	params.Set("albumCount", "0")
+	resp, err := get("search3", params)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.SearchResult3.Song, nil
+}
+
+func GetPlaylists() ([]Playlist, error) {
+	resp, err := get("getPlaylists", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Playlists.Playlist, nil
+}
+
+func GetPlaylist(playlistId string) (*Playlist, error) {
+	params := url.Values{}
+	params.Set("id", playlistId)
+	resp, err := get("getPlaylist", params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.Playlist, nil
+}
+
+func CreatePlaylist(name string, comment string) (*Playlist, error) {
+	params := url.Values{}
+	params.Set("name", name)
+	resp, err := get("createPlaylist", params)
+	if err != nil {
+		return nil, err
+	}
+
+	// The comment can only be set by updating the playlist. The playlist exists already when that fails, so it is
+	// returned anyway and recognised by its id afterwards.
+	playlist := resp.Playlist
+	params = url.Values{}
+	params.Set("playlistId", playlist.Id)
+	params.Set("comment", comment)
+	_, err = get("updatePlaylist", params)
+	if err != nil {
+		getLogger().Warn("could not set the comment of playlist '", name, "': ", err.Error())
+		return &playlist, nil
+	}
+	playlist.Comment = comment
+
+	return &playlist, nil
+}
+
+func AddSongsToPlaylist(playlistId string, songIds []string) error {
+	params := url.Values{}
+	params.Set("playlistId", playlistId)
+	for _, songId := range songIds {
+		params.Add("songIdToAdd", songId)
+	}
+	_, err := get("updatePlaylist", params)
+	return err
+}
+
+// RemoveSongsFromPlaylist removes every occurrence of the songs, Subsonic removes songs by their index in the playlist
+func RemoveSongsFromPlaylist(playlistId string, songIds []string) error {
+	playlist, err := GetPlaylist(playlistId)
+	if err != nil {
+		return err
+	}
+
+	remove := make(map[string]bool)
+	for _, songId := range songIds {
+		remove[songId] = true
+	}
+	params := url.Values{}
+	params.Set("playlistId", playlistId)
+	for index, song := range playlist.Entry {
+		if remove[song.Id] {
+			params.Add("songIndexToRemove", strconv.Itoa(index))
+		}
+	}
+	if len(params["songIndexToRemove"]) == 0 {
+		return nil
+	}
+
+	_, err = get("updatePlaylist", params)
+	return err
+}
+
+func DeletePlaylist(playlistId string) error {
+	params := url.Values{}
+	params.Set("id", playlistId)
+	_, err := get("deletePlaylist", params)
+	return err
+}
+
+func get(endpoint string, params url.Values) (*response, error) {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	if config.Subsonic.Url == "" {
+		return nil, errors.New("no Subsonic server configured")
+	}
+
+	// Token authentication keeps the password out of the requests
+	salt, err := newSalt()
+	if err != nil {
+		return nil, err
+	}
+	hash := md5.Sum([]byte(config.Subsonic.Password + salt))
+	params.Set("u", config.Subsonic.Username)
+	params.Set("t", hex.EncodeToString(hash[:]))
+	params.Set("s", salt)
+	params.Set("v", apiVersion)
+	params.Set("c", clientName)
+	params.Set("f", "json")
+
+	// Subsonic accepts the parameters in the body, which keeps long lists of song ids out of the url
+	endpointUrl := strings.TrimSuffix(config.Subsonic.Url, "/") + "/rest/" + endpoint
+	httpResp, err := http.PostForm(endpointUrl, params)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = httpResp.Body.Close()
+	}()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, errors.New("Subsonic responded with status " + httpResp.Status)
+	}
+
+	var body struct {
+		Response response `json:"subsonic-response"`
+	}
+	err = json.NewDecoder(httpResp.Body).Decode(&body)
+	if err != nil {
+		return nil, err
+	}
+	if body.Response.Status != "ok" {
+		if body.Response.Error != nil {
+			return nil, fmt.Errorf("subsonic error %d: %s", body.Response.Error.Code, body.Response.Error.Message)
+		}
+		return nil, errors.New("subsonic request failed")
+	}
+
+	return &body.Response, nil
+}
+
+// newSalt returns a random salt for the token, which has to be different for every request
+func newSalt() (string, error) {
+	salt := make([]byte, 16)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(salt), nil
+}
+
+func getLogger() *zap.SugaredLogger {
+	logger, _ := zap.NewDevelopment()
+	sugar := logger.Sugar()
+	defer func(logger *zap.Logger) {
+		_ = logger.Sync()
+	}(logger)
+	return sugar
+}
@@ -0,0 +1,108 @@
+package subsonic
+
+import (
+	"api/internal/provider"
+	"strings"
+)
+
+// Provider makes a Subsonic server available as a destination
+type Provider struct{}
+
+func toTrack(song Song) provider.Track {
+	isrc := ""
+	if len(song.ISRC) > 0 {
+		isrc = song.ISRC[0]
+	}
+	return provider.Track{
+		Id:         song.Id,
+		Name:       song.Title,
+		Artists:    []string{song.Artist},
+		Album:      song.Album,
+		ISRC:       isrc,
+		DurationMs: song.Duration * 1000,
+	}
+}
+
+func toPlaylist(playlist Playlist) provider.Playlist {
+	return provider.Playlist{Id: playlist.Id, Name: playlist.Name, Description: playlist.Comment}
+}
+
+// FindTracks searches the library by title, the ISRC tags are compared with the results because Subsonic can
+// not search by ISRC
+func (Provider) FindTracks(tracks []provider.Track) ([]provider.MatchResult, error) {
+	logger := getLogger()
+	results := make([]provider.MatchResult, 0)
+	for _, track := range tracks {
+		songs, err := Search(provider.NormalizeTitle(track.Name))
+		if err != nil {
+			results = append(results, provider.MatchResult{Err: err})
+			continue
+		}
+
+		result := provider.MatchResult{}
+		for _, song := range songs {
+			if provider.IsSameTrack(track, toTrack(song)) {
+				logger.Debug("found track in Subsonic library: ", song.Artist, " - ", song.Title)
+				result.TrackId = song.Id
+				break
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// GetSyncedPlaylist also recognises the playlist by its id, in case its comment could not be set
+func (Provider) GetSyncedPlaylist(sourcePlaylistId string, playlistId string) (*provider.Playlist, error) {
+	playlists, err := GetPlaylists()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, playlist := range playlists {
+		if (playlistId != "" && playlist.Id == playlistId) || strings.Contains(playlist.Comment, "(ID: "+sourcePlaylistId+")") {
+			item := toPlaylist(playlist)
+			return &item, nil
+		}
+	}
+	return nil, nil
+}
+
+func (p Provider) CreatePlaylist(name string, sourcePlaylistId string) (*provider.Playlist, error) {
+	return p.CreateNamedPlaylist(name, name+". Synced with spync. (ID: "+sourcePlaylistId+")")
+}
+
+func (Provider) CreateNamedPlaylist(name string, description string) (*provider.Playlist, error) {
+	playlist, err := CreatePlaylist(name, description)
+	if err != nil {
+		return nil, err
+	}
+
+	item := toPlaylist(*playlist)
+	return &item, nil
+}
+
+func (Provider) GetPlaylistTrackIds(playlistId string) ([]string, error) {
+	playlist, err := GetPlaylist(playlistId)
+	if err != nil {
+		return nil, err
+	}
+
+	trackIds := make([]string, 0)
+	for _, song := range playlist.Entry {
+		trackIds = append(trackIds, song.Id)
+	}
+	return trackIds, nil
+}
+
+func (Provider) AddTracks(playlistId string, trackIds []string) error {
+	return AddSongsToPlaylist(playlistId, trackIds)
+}
+
+func (Provider) RemoveTracks(playlistId string, trackIds []string) error {
+	return RemoveSongsFromPlaylist(playlistId, trackIds)
+}
+
+func (Provider) DeletePlaylist(playlistId string) error {
+	return DeletePlaylist(playlistId)
+}
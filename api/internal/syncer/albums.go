@@ -0,0 +1,145 @@
+package syncer
+
+import (
+	"api/internal/provider"
+	"api/internal/spotify"
+	"api/internal/state"
+	"errors"
+	"time"
+)
+
+// SyncSavedAlbums adds the saved Spotify albums to the Apple Music library. Its progress is kept apart from the
+// playlists in the state.
+func SyncSavedAlbums() (SyncResult, error) {
+	logger := getLogger()
+	logger.Debug("going to sync saved albums")
+
+	source, err := provider.GetSource(provider.Spotify)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	albumSource, ok := source.(provider.AlbumSource)
+	if !ok {
+		return SyncResult{}, errors.New(provider.Spotify + " has no saved albums")
+	}
+	destination, err := provider.GetDestination(provider.AppleMusic)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	albumDestination, ok := destination.(provider.AlbumDestination)
+	if !ok {
+		return SyncResult{}, errors.New(provider.AppleMusic + " can not save albums")
+	}
+
+	stateObj, err := state.GetState()
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	stateObj.Syncing = true
+	err = state.SaveState(stateObj)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	SendToAll(StatusMessage{Syncing: true})
+
+	defer func() {
+		stateObj.Syncing = false
+		_ = state.SaveState(stateObj)
+	}()
+
+	syncStart := time.Now()
+	savedAlbums, err := albumSource.GetSavedAlbums()
+	if err != nil {
+		return SyncResult{}, err
+	}
+	logger.Debug("got saved albums. amount: ", len(savedAlbums))
+
+	albumsState := stateObj.Albums
+	retryAlbumIds := make(map[string]bool)
+	for _, failedAlbum := range albumsState.FailedAlbums {
+		retryAlbumIds[failedAlbum.AlbumId] = true
+	}
+
+	searchAlbums := make([]provider.Album, 0)
+	for _, album := range savedAlbums {
+		if album.AddedAt.After(albumsState.LastSyncDate) || retryAlbumIds[album.Id] {
+			searchAlbums = append(searchAlbums, album)
+		}
+	}
+
+	logger.Debug("going to search for albums. amount: ", len(searchAlbums))
+	matchResults, err := albumDestination.FindAlbums(searchAlbums)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	albumIds := make([]string, 0)
+	failedAlbums := make([]state.AlbumReport, 0)
+	unmatchedAlbums := make([]state.AlbumReport, 0)
+	for i, matchResult := range matchResults {
+		album := searchAlbums[i]
+		if matchResult.Err != nil {
+			logger.Warn("error while searching for album: " + album.Name + ": " + matchResult.Err.Error())
+			failedAlbums = append(failedAlbums, newAlbumReport(album, matchResult.Err.Error()))
+		} else if matchResult.TrackId != "" {
+			logger.Debug("found album: ", album.ArtistNames(), " - ", album.Name)
+			albumIds = append(albumIds, matchResult.TrackId)
+		} else {
+			logger.Warn("could not find album: " + album.Name)
+			unmatchedAlbums = append(unmatchedAlbums, newAlbumReport(album, "not found on Apple Music"))
+		}
+	}
+	matched := len(albumIds)
+
+	albumsState.LastSyncDate = syncStart
+	albumsState.PendingAlbumIds = append(albumsState.PendingAlbumIds, albumIds...)
+	albumsState.FailedAlbums = failedAlbums
+	albumsState.UnmatchedAlbums = unmatchedAlbums
+	stateObj.Albums = albumsState
+	err = state.SaveState(stateObj)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	result := SyncResult{
+		PlaylistId: spotify.SavedAlbumsId,
+		Matched:    matched,
+		Unmatched:  len(unmatchedAlbums),
+		Failed:     len(failedAlbums),
+	}
+
+	for len(stateObj.Albums.PendingAlbumIds) > 0 {
+		batchSize := provider.MaxAlbumsPerRequest
+		if len(stateObj.Albums.PendingAlbumIds) < batchSize {
+			batchSize = len(stateObj.Albums.PendingAlbumIds)
+		}
+
+		err = albumDestination.AddAlbums(stateObj.Albums.PendingAlbumIds[:batchSize])
+		if err != nil {
+			return result, err
+		}
+		result.Added += batchSize
+
+		stateObj.Albums.PendingAlbumIds = stateObj.Albums.PendingAlbumIds[batchSize:]
+		err = state.SaveState(stateObj)
+		if err != nil {
+			return result, err
+		}
+		logger.Debug("added albums to library. amount: ", batchSize, ", remaining: ", len(stateObj.Albums.PendingAlbumIds))
+	}
+
+	SendToAll(StatusMessage{Syncing: false, Result: &result})
+
+	return result, nil
+}
+
+func newAlbumReport(album provider.Album, reason string) state.AlbumReport {
+	return state.AlbumReport{
+		AlbumId: album.Id,
+		Name:    album.Name,
+		Artists: album.ArtistNames(),
+		Reason:  reason,
+	}
+}
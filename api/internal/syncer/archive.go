@@ -0,0 +1,194 @@
+package syncer
+
+import (
+	"api/internal/provider"
+	"api/internal/state"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultArchiveNameTemplate = "{name} {year}-W{week}"
+
+// syncArchive copies every new snapshot of a playlist into a new destination playlist, for playlists like
+// Discover Weekly that are replaced as a whole. Sources without snapshot ids are compared by their tracks.
+func (r *pairRun) syncArchive(tracks []provider.Track) (SyncResult, error) {
+	logger := getLogger()
+	result := SyncResult{}
+
+	playlistState := r.getState()
+	saveProgress := func(remaining []string) error {
+		playlistState.PendingTrackIds = remaining
+		return r.saveState(playlistState)
+	}
+	saveState := func() error {
+		return r.saveState(playlistState)
+	}
+
+	snapshotId := r.sourcePlaylist.SnapshotId
+	if snapshotId == "" {
+		snapshotId = getTracksSnapshotId(tracks)
+	}
+
+	if snapshotId == playlistState.LastSnapshotId {
+		// Finish the latest archive when an earlier sync failed halfway
+		if len(playlistState.PendingTrackIds) > 0 && len(playlistState.Archives) > 0 {
+			archivePlaylistId := playlistState.Archives[len(playlistState.Archives)-1].PlaylistId
+			added, err := addTracksInBatches(playlistState.PendingTrackIds, func(trackIds []string) error {
+				return r.destination.AddTracks(archivePlaylistId, trackIds)
+			}, saveProgress)
+			result.Added = added
+			if err != nil {
+				return result, err
+			}
+		}
+		logger.Debug("snapshot is already archived: ", snapshotId)
+		return result, nil
+	}
+
+	trackIds, failedTracks, unmatchedTracks, err := findTrackIds(r.destination, tracks)
+	if err != nil {
+		return result, err
+	}
+	matchedTrackIds := make([]string, 0)
+	for _, trackId := range trackIds {
+		if trackId != "" {
+			matchedTrackIds = append(matchedTrackIds, trackId)
+		}
+	}
+	matched := len(matchedTrackIds)
+	matchedTrackIds, duplicates := removeDuplicates(matchedTrackIds, nil, r.config.KeepDuplicates)
+
+	now := time.Now()
+	name := formatArchiveName(r.config.Archive.NameTemplate, r.sourcePlaylist.Name, now)
+	archivePlaylist, err := r.destination.CreateNamedPlaylist(name,
+		r.sourcePlaylist.Name+". Archived by spync. (Archive of: "+r.config.PlaylistId+")")
+	if err != nil {
+		return result, err
+	}
+	logger.Debug("created archive playlist with name: " + name)
+
+	playlistState.LastSyncDate = now
+	playlistState.LastSnapshotId = snapshotId
+	playlistState.Archives = append(playlistState.Archives, state.ArchiveState{
+		PlaylistId:  archivePlaylist.Id,
+		Name:        name,
+		SnapshotId:  snapshotId,
+		CreatedDate: now,
+	})
+	playlistState.SkippedDuplicates = duplicates
+	playlistState.FailedTracks = failedTracks
+	playlistState.UnmatchedTracks = unmatchedTracks
+	err = saveProgress(matchedTrackIds)
+	if err != nil {
+		return result, err
+	}
+
+	result.Matched = matched
+	result.Unmatched = len(unmatchedTracks)
+	result.Failed = len(failedTracks)
+	result.Duplicates = duplicates
+	result.Added, err = addTracksInBatches(matchedTrackIds, func(trackIds []string) error {
+		return r.destination.AddTracks(archivePlaylist.Id, trackIds)
+	}, saveProgress)
+	if err != nil {
+		return result, err
+	}
+
+	if r.config.Archive.AllTime {
+		err = r.addToAllTimePlaylist(&playlistState, matchedTrackIds, saveState)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if r.config.Archive.Retention > 0 {
+		err = removeExpiredArchives(&playlistState, r.config.Archive.Retention, r.destination.DeletePlaylist)
+		if err != nil {
+			result.Warnings = append(result.Warnings, err.Error())
+		}
+	}
+
+	return result, saveState()
+}
+
+// getTracksSnapshotId stands in for the snapshot id of sources that have none, it changes whenever the tracks do
+func getTracksSnapshotId(tracks []provider.Track) string {
+	hash := sha1.New()
+	for _, track := range tracks {
+		hash.Write([]byte(track.Id + "\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// addToAllTimePlaylist adds the tracks of every snapshot to a single playlist that only grows. The state is saved as
+// soon as the playlist is created, so a failing sync does not create it again.
+func (r *pairRun) addToAllTimePlaylist(playlistState *state.PlaylistState, trackIds []string, saveState func() error) error {
+	logger := getLogger()
+	existingTrackIds := make([]string, 0)
+	if playlistState.AllTimePlaylistId == "" {
+		allTimePlaylist, err := r.destination.CreateNamedPlaylist(r.sourcePlaylist.Name+" (all-time)",
+			r.sourcePlaylist.Name+". All tracks ever, synced with spync. (All-time of: "+r.config.PlaylistId+")")
+		if err != nil {
+			return err
+		}
+		playlistState.AllTimePlaylistId = allTimePlaylist.Id
+		logger.Debug("created all-time playlist with name: " + allTimePlaylist.Name)
+		err = saveState()
+		if err != nil {
+			return err
+		}
+	} else {
+		var err error
+		existingTrackIds, err = r.destination.GetPlaylistTrackIds(playlistState.AllTimePlaylistId)
+		if err != nil {
+			return err
+		}
+	}
+
+	allTimePlaylistId := playlistState.AllTimePlaylistId
+	newTrackIds, _ := removeDuplicates(trackIds, existingTrackIds, false)
+	_, err := addTracksInBatches(newTrackIds, func(trackIds []string) error {
+		return r.destination.AddTracks(allTimePlaylistId, trackIds)
+	}, nil)
+	return err
+}
+
+// removeExpiredArchives deletes the oldest archives with deletePlaylist until only retention archives are left. Not
+// every destination can delete playlists, Apple Music only through an undocumented endpoint, so it may fail. Archives
+// that can not be deleted are kept in the state, so deleting them is tried again during the next sync.
+func removeExpiredArchives(playlistState *state.PlaylistState, retention int, deletePlaylist func(playlistId string) error) error {
+	logger := getLogger()
+	for len(playlistState.Archives) > retention {
+		oldest := playlistState.Archives[0]
+		err := deletePlaylist(oldest.PlaylistId)
+		if err != nil {
+			logger.Warn("could not delete archive '", oldest.Name, "': ", err.Error())
+			return errors.New("could not delete expired archive '" + oldest.Name + "': " + err.Error())
+		}
+		logger.Debug("deleted archive: " + oldest.Name)
+		playlistState.Archives = playlistState.Archives[1:]
+	}
+	return nil
+}
+
+// formatArchiveName fills in the {name}, {year}, {week} and {date} placeholders of the template. The year and week
+// are the ISO 8601 year and week of the date.
+func formatArchiveName(template string, name string, date time.Time) string {
+	if template == "" {
+		template = defaultArchiveNameTemplate
+	}
+
+	year, week := date.ISOWeek()
+	replacer := strings.NewReplacer(
+		"{name}", name,
+		"{year}", strconv.Itoa(year),
+		"{week}", fmt.Sprintf("%02d", week),
+		"{date}", date.Format("2006-01-02"),
+	)
+	return replacer.Replace(template)
+}
@@ -0,0 +1,84 @@
+package syncer
+
+import (
+	"api/internal/state"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFormatArchiveName(t *testing.T) {
+	date := time.Date(2024, time.December, 30, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{name: "default template", template: "", want: "Discover Weekly 2025-W01"},
+		{name: "date", template: "{name} ({date})", want: "Discover Weekly (2024-12-30)"},
+		{name: "year and week", template: "{year}/{week} {name}", want: "2025/01 Discover Weekly"},
+		{name: "repeated placeholder", template: "{name} {name}", want: "Discover Weekly Discover Weekly"},
+		{name: "unknown placeholder", template: "{name} {month}", want: "Discover Weekly {month}"},
+		{name: "no placeholders", template: "Archive", want: "Archive"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := formatArchiveName(test.template, "Discover Weekly", date); got != test.want {
+				t.Errorf("formatArchiveName(%q) = %q, want %q", test.template, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRemoveExpiredArchives(t *testing.T) {
+	archives := []state.ArchiveState{
+		{PlaylistId: "1", Name: "Week 1"},
+		{PlaylistId: "2", Name: "Week 2"},
+		{PlaylistId: "3", Name: "Week 3"},
+	}
+
+	tests := []struct {
+		name        string
+		retention   int
+		failId      string
+		wantDeleted []string
+		wantKept    []string
+		wantErr     bool
+	}{
+		{name: "within retention", retention: 3, wantDeleted: []string{}, wantKept: []string{"1", "2", "3"}},
+		{name: "oldest first", retention: 1, wantDeleted: []string{"1", "2"}, wantKept: []string{"3"}},
+		{name: "failed deletion is kept", retention: 1, failId: "2", wantDeleted: []string{"1"}, wantKept: []string{"2", "3"}, wantErr: true},
+		{name: "first deletion fails", retention: 2, failId: "1", wantDeleted: []string{}, wantKept: []string{"1", "2", "3"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			playlistState := state.PlaylistState{Archives: append([]state.ArchiveState{}, archives...)}
+			deleted := make([]string, 0)
+			err := removeExpiredArchives(&playlistState, test.retention, func(playlistId string) error {
+				if playlistId == test.failId {
+					return errors.New("not allowed")
+				}
+				deleted = append(deleted, playlistId)
+				return nil
+			})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("removeExpiredArchives() error = %v, wantErr %v", err, test.wantErr)
+			}
+
+			kept := make([]string, 0)
+			for _, archive := range playlistState.Archives {
+				kept = append(kept, archive.PlaylistId)
+			}
+			if !reflect.DeepEqual(deleted, test.wantDeleted) {
+				t.Errorf("deleted %v, want %v", deleted, test.wantDeleted)
+			}
+			if !reflect.DeepEqual(kept, test.wantKept) {
+				t.Errorf("kept %v, want %v", kept, test.wantKept)
+			}
+		})
+	}
+}
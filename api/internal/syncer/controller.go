@@ -2,6 +2,8 @@ package syncer
 
 import (
 	"api/internal/applemusic"
+	"api/internal/configuration"
+	"api/internal/provider"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -62,23 +64,192 @@ func SyncPlaylistEndpoint(c *gin.Context) {
 		return
 	}
 
-	err = SyncPlaylist(playlistId)
+	result, err := SyncPlaylist(playlistId)
 	if err != nil {
 		c.JSON(400, gin.H{
 			"message": err.Error(),
+			"result":  result,
 		})
 		return
 	}
 
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		c.JSON(500, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	destinationResults := syncPlaylistDestinations(playlistId, config.GetPlaylistConfig(playlistId))
+
+	message := "Sync done"
+	if result.IsPartial() {
+		message = "Sync partially done"
+	}
+
 	c.JSON(200, gin.H{
-		"message": "Sync done",
+		"message":      message,
+		"result":       result,
+		"destinations": destinationResults,
 	})
 }
 
+func GetPlaylistIdsToSyncEndpoint(c *gin.Context) {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		c.JSON(500, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	playlistIds, err := GetPlaylistIdsToSync(config)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, playlistIds)
+}
+
 func SyncPlaylistsEndpoint(c *gin.Context) {
-	SyncAllPlaylists()
+	results := SyncAllPlaylists()
 
 	c.JSON(200, gin.H{
 		"message": "Sync done",
+		"results": results,
+	})
+}
+
+func SyncTargetEndpoint(c *gin.Context) {
+	targetId := c.Param("targetId")
+
+	err := applemusic.CheckAuth()
+	if err != nil {
+		c.JSON(400, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	result, err := SyncTarget(targetId)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"message": err.Error(),
+			"result":  result,
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Sync done",
+		"result":  result,
+	})
+}
+
+func SyncSavedAlbumsEndpoint(c *gin.Context) {
+	err := applemusic.CheckAuth()
+	if err != nil {
+		c.JSON(400, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	result, err := SyncSavedAlbums()
+	if err != nil {
+		c.JSON(400, gin.H{
+			"message": err.Error(),
+			"result":  result,
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Sync done",
+		"result":  result,
+	})
+}
+
+func SyncAppleMusicPlaylistEndpoint(c *gin.Context) {
+	playlistId := c.Param("playlistId")
+
+	err := applemusic.CheckAuth()
+	if err != nil {
+		c.JSON(400, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	result, err := SyncAppleMusicPlaylist(playlistId)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"message": err.Error(),
+			"result":  result,
+		})
+		return
+	}
+
+	message := "Sync done"
+	if result.IsPartial() {
+		message = "Sync partially done"
+	}
+
+	c.JSON(200, gin.H{
+		"message": message,
+		"result":  result,
+	})
+}
+
+func SyncLocalPlaylistEndpoint(c *gin.Context) {
+	playlistId := c.Param("playlistId")
+
+	result, err := SyncLocalPlaylist(playlistId)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"message": err.Error(),
+			"result":  result,
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Sync done",
+		"result":  result,
+	})
+}
+
+func SyncPairEndpoint(c *gin.Context) {
+	pairId := c.Param("pairId")
+
+	result, err := SyncPair(pairId)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"message": err.Error(),
+			"result":  result,
+		})
+		return
+	}
+
+	message := "Sync done"
+	if result.IsPartial() {
+		message = "Sync partially done"
+	}
+
+	c.JSON(200, gin.H{
+		"message": message,
+		"result":  result,
+	})
+}
+
+func GetProvidersEndpoint(c *gin.Context) {
+	sources, destinations := provider.GetNames()
+
+	c.JSON(200, gin.H{
+		"sources":      sources,
+		"destinations": destinations,
 	})
 }
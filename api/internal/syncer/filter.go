@@ -0,0 +1,70 @@
+package syncer
+
+import (
+	"api/internal/configuration"
+	"api/internal/provider"
+	"api/internal/state"
+	"fmt"
+	"strings"
+)
+
+// filterTracks applies the filters of a playlist. The tracks that are dropped are returned with the reason.
+func filterTracks(tracks []provider.Track, filters configuration.FilterConfig) ([]provider.Track, []state.TrackReport) {
+	kept := make([]provider.Track, 0)
+	filtered := make([]state.TrackReport, 0)
+	for i, reason := range getFilterReasons(tracks, filters) {
+		if reason != "" {
+			filtered = append(filtered, newReport(tracks[i], reason))
+		} else {
+			kept = append(kept, tracks[i])
+		}
+	}
+
+	return kept, filtered
+}
+
+// getFilterReasons returns the reason why each track is dropped, which is empty for the tracks that are kept
+func getFilterReasons(tracks []provider.Track, filters configuration.FilterConfig) []string {
+	excludedArtists := make(map[string]bool)
+	for _, artist := range filters.ExcludeArtists {
+		excludedArtists[strings.ToLower(artist)] = true
+	}
+
+	reasons := make([]string, len(tracks))
+	kept := 0
+	for i, track := range tracks {
+		reason := getFilterReason(track, filters, excludedArtists)
+		if reason == "" && filters.MaxTracks > 0 && kept >= filters.MaxTracks {
+			reason = fmt.Sprintf("playlist is capped at %d tracks", filters.MaxTracks)
+		}
+
+		if reason == "" {
+			kept++
+		}
+		reasons[i] = reason
+	}
+
+	return reasons
+}
+
+func getFilterReason(track provider.Track, filters configuration.FilterConfig, excludedArtists map[string]bool) string {
+	if filters.ExcludeExplicit && track.Explicit {
+		return "explicit"
+	}
+
+	durationSeconds := track.DurationMs / 1000
+	if filters.MinDurationSeconds > 0 && durationSeconds < filters.MinDurationSeconds {
+		return fmt.Sprintf("shorter than %d seconds", filters.MinDurationSeconds)
+	}
+	if filters.MaxDurationSeconds > 0 && durationSeconds > filters.MaxDurationSeconds {
+		return fmt.Sprintf("longer than %d seconds", filters.MaxDurationSeconds)
+	}
+
+	for _, artist := range track.Artists {
+		if excludedArtists[strings.ToLower(artist)] {
+			return "excluded artist: " + artist
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,87 @@
+package syncer
+
+import (
+	"api/internal/configuration"
+	"api/internal/provider"
+	"reflect"
+	"testing"
+)
+
+func TestFilterTracks(t *testing.T) {
+	tracks := []provider.Track{
+		{Id: "1", Name: "Short", Artists: []string{"Artist"}, DurationMs: 30000},
+		{Id: "2", Name: "Explicit", Artists: []string{"Artist"}, DurationMs: 200000, Explicit: true},
+		{Id: "3", Name: "Long", Artists: []string{"Artist"}, DurationMs: 900000},
+		{Id: "4", Name: "Excluded", Artists: []string{"Other", "Unwanted Band"}, DurationMs: 200000},
+		{Id: "5", Name: "First", Artists: []string{"Artist"}, DurationMs: 200000},
+		{Id: "6", Name: "Second", Artists: []string{"Artist"}, DurationMs: 200000},
+	}
+
+	tests := []struct {
+		name        string
+		filters     configuration.FilterConfig
+		wantKept    []string
+		wantReasons map[string]string
+	}{
+		{
+			name:        "no filters",
+			filters:     configuration.FilterConfig{},
+			wantKept:    []string{"1", "2", "3", "4", "5", "6"},
+			wantReasons: map[string]string{},
+		},
+		{
+			name:        "explicit",
+			filters:     configuration.FilterConfig{ExcludeExplicit: true},
+			wantKept:    []string{"1", "3", "4", "5", "6"},
+			wantReasons: map[string]string{"2": "explicit"},
+		},
+		{
+			name:     "duration",
+			filters:  configuration.FilterConfig{MinDurationSeconds: 60, MaxDurationSeconds: 600},
+			wantKept: []string{"2", "4", "5", "6"},
+			wantReasons: map[string]string{
+				"1": "shorter than 60 seconds",
+				"3": "longer than 600 seconds",
+			},
+		},
+		{
+			name:        "excluded artist ignores case",
+			filters:     configuration.FilterConfig{ExcludeArtists: []string{"unwanted band"}},
+			wantKept:    []string{"1", "2", "3", "5", "6"},
+			wantReasons: map[string]string{"4": "excluded artist: Unwanted Band"},
+		},
+		{
+			name:     "cap counts kept tracks only",
+			filters:  configuration.FilterConfig{ExcludeExplicit: true, MaxTracks: 2},
+			wantKept: []string{"1", "3"},
+			wantReasons: map[string]string{
+				"2": "explicit",
+				"4": "playlist is capped at 2 tracks",
+				"5": "playlist is capped at 2 tracks",
+				"6": "playlist is capped at 2 tracks",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kept, filtered := filterTracks(tracks, test.filters)
+
+			keptIds := make([]string, 0)
+			for _, track := range kept {
+				keptIds = append(keptIds, track.Id)
+			}
+			if !reflect.DeepEqual(keptIds, test.wantKept) {
+				t.Errorf("filterTracks() kept %v, want %v", keptIds, test.wantKept)
+			}
+
+			reasons := make(map[string]string)
+			for _, report := range filtered {
+				reasons[report.TrackId] = report.Reason
+			}
+			if !reflect.DeepEqual(reasons, test.wantReasons) {
+				t.Errorf("filterTracks() filtered %v, want %v", reasons, test.wantReasons)
+			}
+		})
+	}
+}
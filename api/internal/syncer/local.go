@@ -0,0 +1,141 @@
+package syncer
+
+import (
+	"api/internal/configuration"
+	"api/internal/local"
+	"api/internal/provider"
+	"api/internal/state"
+	"errors"
+	"os"
+	"time"
+)
+
+// SyncLocalPlaylist writes a Spotify playlist as a playlist file with the matching tracks of the music directory.
+// The file is rewritten completely on every sync, tracks that are missing locally are reported.
+func SyncLocalPlaylist(playlistId string) (SyncResult, error) {
+	library, err := scanLocalLibrary()
+	if err != nil {
+		return SyncResult{}, err
+	}
+	return syncLocalPlaylist(playlistId, library)
+}
+
+// scanLocalLibrary scans the configured music directory, syncing all playlists scans it only once
+func scanLocalLibrary() (*local.Library, error) {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	if config.Local.MusicDirectory == "" || config.Local.PlaylistDirectory == "" {
+		return nil, errors.New("no music or playlist directory configured")
+	}
+	return local.ScanLibrary(config.Local.MusicDirectory)
+}
+
+func syncLocalPlaylist(playlistId string, library *local.Library) (SyncResult, error) {
+	logger := getLogger()
+	logger.Debug("going to sync playlist to local files: " + playlistId)
+
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return SyncResult{}, err
+	}
+	source, err := provider.GetSource(provider.Spotify)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	stateObj, err := state.GetState()
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	stateObj.Syncing = true
+	err = state.SaveState(stateObj)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	SendToAll(StatusMessage{Syncing: true})
+
+	defer func() {
+		stateObj.Syncing = false
+		_ = state.SaveState(stateObj)
+	}()
+
+	sourcePlaylist, err := source.GetPlaylist(playlistId)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	syncStart := time.Now()
+	tracks, skippedTracks, err := source.GetPlaylistTracks(playlistId)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	logger.Debug("got tracks. amount: ", len(tracks))
+
+	tracks, filteredTracks := filterTracks(tracks, config.GetPlaylistConfig(playlistId).Filters)
+
+	localTracks := make([]provider.Track, 0)
+	missingTracks := make([]state.TrackReport, 0)
+	for _, track := range tracks {
+		localTrack := library.FindTrack(track)
+		if localTrack.Id == "" {
+			missingTracks = append(missingTracks, newReport(track, "missing locally"))
+			continue
+		}
+		localTracks = append(localTracks, localTrack)
+	}
+
+	if stateObj.LocalPlaylists == nil {
+		stateObj.LocalPlaylists = make(map[string]state.LocalPlaylistState)
+	}
+	playlistState := stateObj.LocalPlaylists[playlistId]
+
+	// Playlists with the same name get the id in their file name, so they do not overwrite each other
+	path := local.GetPlaylistPath(config.Local.PlaylistDirectory, sourcePlaylist.Name, config.Local.Format)
+	for otherId, otherState := range stateObj.LocalPlaylists {
+		if otherId != playlistId && otherState.Path == path {
+			path = local.GetPlaylistPath(config.Local.PlaylistDirectory, sourcePlaylist.Name+" ("+playlistId+")",
+				config.Local.Format)
+			break
+		}
+	}
+	err = local.WritePlaylist(path, sourcePlaylist.Name, "Synced with spync. (ID: "+playlistId+")", library,
+		localTracks)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	logger.Debug("wrote playlist file: " + path)
+
+	// The playlist was renamed, or the format changed
+	if playlistState.Path != "" && playlistState.Path != path {
+		err = os.Remove(playlistState.Path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Warn("could not remove old playlist file: ", err.Error())
+		}
+	}
+
+	playlistState.Path = path
+	playlistState.LastSyncDate = syncStart
+	playlistState.MissingTracks = missingTracks
+	playlistState.SkippedTracks = skippedTracks
+	playlistState.FilteredTracks = filteredTracks
+	stateObj.LocalPlaylists[playlistId] = playlistState
+	err = state.SaveState(stateObj)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	result := SyncResult{
+		PlaylistId: playlistId,
+		Matched:    len(localTracks),
+		Unmatched:  len(missingTracks),
+		Filtered:   len(filteredTracks),
+		Skipped:    len(skippedTracks),
+	}
+
+	SendToAll(StatusMessage{Syncing: false, Result: &result})
+	return result, nil
+}
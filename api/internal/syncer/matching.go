@@ -0,0 +1,37 @@
+package syncer
+
+import (
+	"api/internal/provider"
+	"api/internal/state"
+)
+
+// findTrackIds searches the destination for the tracks and returns an id for every track. The id is empty when the
+// track was not found. Tracks that could not be searched because of an error are returned separately from the
+// tracks that were not found.
+func findTrackIds(destination provider.Destination, tracks []provider.Track) ([]string, []state.TrackReport, []state.TrackReport, error) {
+	logger := getLogger()
+	logger.Debug("going to search for tracks. amount: ", len(tracks))
+	matchResults, err := destination.FindTracks(tracks)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	trackIds := make([]string, len(tracks))
+	failedTracks := make([]state.TrackReport, 0)
+	unmatchedTracks := make([]state.TrackReport, 0)
+	for i, matchResult := range matchResults {
+		track := tracks[i]
+		if matchResult.Err != nil {
+			logger.Warn("error while searching for track: " + track.Name + ": " + matchResult.Err.Error())
+			failedTracks = append(failedTracks, newReport(track, matchResult.Err.Error()))
+		} else if matchResult.TrackId != "" {
+			logger.Debug("found track: ", track.ArtistNames(), " - ", track.Name)
+			trackIds[i] = matchResult.TrackId
+		} else {
+			logger.Warn("could not find track: " + track.Name)
+			unmatchedTracks = append(unmatchedTracks, newReport(track, "not found"))
+		}
+	}
+
+	return trackIds, failedTracks, unmatchedTracks, nil
+}
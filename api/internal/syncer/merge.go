@@ -0,0 +1,301 @@
+package syncer
+
+import (
+	"api/internal/configuration"
+	"api/internal/provider"
+	"api/internal/state"
+	"errors"
+	"sort"
+	"time"
+)
+
+type sourceItem struct {
+	sourceId string
+	track    provider.Track
+}
+
+// SyncTarget merges the source playlists of a target into one playlist of the destination. Every track keeps track of
+// the sources it came from, so it is only removed once it is gone from all of them.
+func SyncTarget(targetId string) (SyncResult, error) {
+	logger := getLogger()
+	logger.Debug("going to sync target: " + targetId)
+
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return SyncResult{}, err
+	}
+	targetConfig, ok := config.Targets[targetId]
+	if !ok {
+		return SyncResult{}, errors.New("unknown target: " + targetId)
+	}
+
+	sourceName := targetConfig.Source
+	if sourceName == "" {
+		sourceName = provider.Spotify
+	}
+	destinationName := targetConfig.Destination
+	if destinationName == "" {
+		destinationName = provider.AppleMusic
+	}
+	source, err := provider.GetSource(sourceName)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	destination, err := provider.GetDestination(destinationName)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	stateObj, err := state.GetState()
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	stateObj.Syncing = true
+	err = state.SaveState(stateObj)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	SendToAll(StatusMessage{Syncing: true})
+
+	defer func() {
+		stateObj.Syncing = false
+		_ = state.SaveState(stateObj)
+	}()
+
+	sources := make([][]sourceItem, 0)
+	skipped := 0
+	for _, sourceId := range targetConfig.SourceIds {
+		tracks, skippedTracks, err := source.GetPlaylistTracks(sourceId)
+		if err != nil {
+			return SyncResult{}, err
+		}
+		logger.Debug("got tracks of source ", sourceId, ". amount: ", len(tracks))
+		skipped += len(skippedTracks)
+
+		items := make([]sourceItem, 0)
+		for _, track := range tracks {
+			items = append(items, sourceItem{sourceId: sourceId, track: track})
+		}
+		sources = append(sources, items)
+	}
+
+	mergedTracks, tracks := mergeItems(orderItems(sources, targetConfig.Ordering))
+	logger.Debug("merged sources. amount: ", len(mergedTracks))
+
+	if stateObj.Targets == nil {
+		stateObj.Targets = make(map[string]state.TargetState)
+	}
+	targetState := stateObj.Targets[targetId]
+
+	knownTracks := make(map[string]state.MergedTrack)
+	for _, track := range targetState.Tracks {
+		knownTracks[track.TrackId] = track
+		if track.ISRC != "" {
+			knownTracks[track.ISRC] = track
+		}
+	}
+
+	// Tracks that are already matched keep their destination id, the others are searched
+	searchTracks := make([]provider.Track, 0)
+	searchIndexes := make([]int, 0)
+	present := make(map[string]bool)
+	for i, track := range mergedTracks {
+		known, isKnown := knownTracks[track.TrackId]
+		if !isKnown && track.ISRC != "" {
+			known, isKnown = knownTracks[track.ISRC]
+		}
+
+		if isKnown && known.DestinationId != "" {
+			mergedTracks[i].DestinationId = known.DestinationId
+			present[known.TrackId] = true
+		} else {
+			searchTracks = append(searchTracks, tracks[i])
+			searchIndexes = append(searchIndexes, i)
+		}
+	}
+
+	syncStart := time.Now()
+	trackIds, failedTracks, unmatchedTracks, err := findTrackIds(destination, searchTracks)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	newTrackIds := make([]string, 0)
+	failed := make(map[string]bool)
+	for _, failedTrack := range failedTracks {
+		failed[failedTrack.TrackId] = true
+	}
+	for i, trackId := range trackIds {
+		mergedTracks[searchIndexes[i]].DestinationId = trackId
+		if trackId != "" {
+			newTrackIds = append(newTrackIds, trackId)
+		}
+	}
+
+	// Failed tracks are left out, so they are searched again during the next sync
+	targetTracks := make([]state.MergedTrack, 0)
+	wanted := make(map[string]bool)
+	for _, track := range mergedTracks {
+		if !failed[track.TrackId] {
+			targetTracks = append(targetTracks, track)
+			wanted[track.DestinationId] = true
+		}
+	}
+
+	// Tracks that are gone from every source are removed
+	removedTrackIds := make([]string, 0)
+	for _, track := range targetState.Tracks {
+		if !present[track.TrackId] && track.DestinationId != "" && !wanted[track.DestinationId] {
+			removedTrackIds = append(removedTrackIds, track.DestinationId)
+		}
+	}
+
+	existingTrackIds := make([]string, 0)
+	if targetState.PlaylistId == "" {
+		destinationPlaylist, err := destination.CreateNamedPlaylist(targetConfig.Name,
+			targetConfig.Name+". Merged by spync. (Target: "+targetId+")")
+		if err != nil {
+			return SyncResult{}, err
+		}
+		targetState.PlaylistId = destinationPlaylist.Id
+		logger.Debug("created target playlist with name: " + destinationPlaylist.Name)
+	} else {
+		existingTrackIds, err = destination.GetPlaylistTrackIds(targetState.PlaylistId)
+		if err != nil {
+			return SyncResult{}, err
+		}
+	}
+
+	result := SyncResult{
+		PlaylistId: targetId,
+		Matched:    len(newTrackIds),
+		Unmatched:  len(unmatchedTracks),
+		Failed:     len(failedTracks),
+		Skipped:    skipped,
+	}
+
+	if len(removedTrackIds) > 0 {
+		err = destination.RemoveTracks(targetState.PlaylistId, removedTrackIds)
+		if err != nil {
+			// Keep the removed tracks around without a source, so removing them is tried again
+			logger.Warn("could not remove tracks from target: ", err.Error())
+			result.Warnings = append(result.Warnings, "could not remove tracks from target: "+err.Error())
+			for _, track := range targetState.Tracks {
+				if !present[track.TrackId] && track.DestinationId != "" && !wanted[track.DestinationId] {
+					track.SourceIds = make([]string, 0)
+					targetTracks = append(targetTracks, track)
+				}
+			}
+		} else {
+			result.Removed = len(removedTrackIds)
+		}
+	}
+
+	// Copied, appending to the pending tracks could otherwise write into the slice of the saved state
+	addTrackIds := make([]string, 0, len(targetState.PendingTrackIds)+len(newTrackIds))
+	addTrackIds = append(addTrackIds, targetState.PendingTrackIds...)
+	addTrackIds = append(addTrackIds, newTrackIds...)
+	addTrackIds, result.Duplicates = removeDuplicates(addTrackIds, existingTrackIds, false)
+
+	targetState.LastSyncDate = syncStart
+	targetState.Tracks = targetTracks
+	targetState.FailedTracks = failedTracks
+	targetState.PendingTrackIds = addTrackIds
+	stateObj.Targets[targetId] = targetState
+	err = state.SaveState(stateObj)
+	if err != nil {
+		return result, err
+	}
+
+	playlistId := targetState.PlaylistId
+	result.Added, err = addTracksInBatches(addTrackIds, func(trackIds []string) error {
+		return destination.AddTracks(playlistId, trackIds)
+	}, func(remaining []string) error {
+		targetState.PendingTrackIds = remaining
+		stateObj.Targets[targetId] = targetState
+		return state.SaveState(stateObj)
+	})
+	if err != nil {
+		return result, err
+	}
+
+	SendToAll(StatusMessage{Syncing: false, Result: &result})
+
+	return result, nil
+}
+
+// orderItems combines the items of the sources into a single list. The target playlist is only appended to, so the
+// order is kept when it is created and new tracks of later syncs end up at the end.
+func orderItems(sources [][]sourceItem, ordering string) []sourceItem {
+	items := make([]sourceItem, 0)
+	switch ordering {
+	case configuration.OrderingInterleaved:
+		for i := 0; ; i++ {
+			taken := false
+			for _, source := range sources {
+				if i < len(source) {
+					items = append(items, source[i])
+					taken = true
+				}
+			}
+			if !taken {
+				break
+			}
+		}
+	case configuration.OrderingDateAdded:
+		for _, source := range sources {
+			items = append(items, source...)
+		}
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].track.AddedAt.Before(items[j].track.AddedAt)
+		})
+	default:
+		for _, source := range sources {
+			items = append(items, source...)
+		}
+	}
+	return items
+}
+
+// mergeItems removes tracks that occur more than once, by id or ISRC. The sources of a track are combined.
+func mergeItems(items []sourceItem) ([]state.MergedTrack, []provider.Track) {
+	mergedTracks := make([]state.MergedTrack, 0)
+	tracks := make([]provider.Track, 0)
+	indexes := make(map[string]int)
+	for _, sourceItem := range items {
+		track := sourceItem.track
+
+		index, exists := indexes[track.Id]
+		if !exists && track.ISRC != "" {
+			index, exists = indexes[track.ISRC]
+		}
+
+		if exists {
+			mergedTracks[index].SourceIds = appendSourceId(mergedTracks[index].SourceIds, sourceItem.sourceId)
+			continue
+		}
+
+		indexes[track.Id] = len(mergedTracks)
+		if track.ISRC != "" {
+			indexes[track.ISRC] = len(mergedTracks)
+		}
+		mergedTracks = append(mergedTracks, state.MergedTrack{
+			TrackId:   track.Id,
+			ISRC:      track.ISRC,
+			SourceIds: []string{sourceItem.sourceId},
+		})
+		tracks = append(tracks, track)
+	}
+	return mergedTracks, tracks
+}
+
+func appendSourceId(sourceIds []string, sourceId string) []string {
+	for _, existing := range sourceIds {
+		if existing == sourceId {
+			return sourceIds
+		}
+	}
+	return append(sourceIds, sourceId)
+}
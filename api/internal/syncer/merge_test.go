@@ -0,0 +1,103 @@
+package syncer
+
+import (
+	"api/internal/configuration"
+	"api/internal/provider"
+	"api/internal/state"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newSourceItem(sourceId string, trackId string, isrc string, addedAt string) sourceItem {
+	addedAtTime, _ := time.Parse(time.RFC3339, addedAt)
+	return sourceItem{
+		sourceId: sourceId,
+		track:    provider.Track{Id: trackId, ISRC: isrc, AddedAt: addedAtTime},
+	}
+}
+
+func TestOrderItems(t *testing.T) {
+	sources := [][]sourceItem{
+		{
+			newSourceItem("a", "a1", "", "2024-01-03T00:00:00Z"),
+			newSourceItem("a", "a2", "", "2024-01-05T00:00:00Z"),
+			newSourceItem("a", "a3", "", "2024-01-01T00:00:00Z"),
+		},
+		{
+			newSourceItem("b", "b1", "", "2024-01-02T00:00:00Z"),
+			newSourceItem("b", "b2", "", "2024-01-03T00:00:00Z"),
+		},
+	}
+
+	tests := []struct {
+		ordering string
+		want     []string
+	}{
+		{ordering: "", want: []string{"a1", "a2", "a3", "b1", "b2"}},
+		{ordering: configuration.OrderingConcatenated, want: []string{"a1", "a2", "a3", "b1", "b2"}},
+		{ordering: configuration.OrderingInterleaved, want: []string{"a1", "b1", "a2", "b2", "a3"}},
+		{ordering: configuration.OrderingDateAdded, want: []string{"a3", "b1", "a1", "b2", "a2"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ordering, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, item := range orderItems(sources, test.ordering) {
+				got = append(got, item.track.Id)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("orderItems(%q) = %v, want %v", test.ordering, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMergeItems(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []sourceItem
+		want  []state.MergedTrack
+	}{
+		{
+			name:  "distinct tracks",
+			items: []sourceItem{newSourceItem("a", "1", "ISRC1", ""), newSourceItem("b", "2", "ISRC2", "")},
+			want: []state.MergedTrack{
+				{TrackId: "1", ISRC: "ISRC1", SourceIds: []string{"a"}},
+				{TrackId: "2", ISRC: "ISRC2", SourceIds: []string{"b"}},
+			},
+		},
+		{
+			name:  "same id in two sources",
+			items: []sourceItem{newSourceItem("a", "1", "", ""), newSourceItem("b", "1", "", "")},
+			want:  []state.MergedTrack{{TrackId: "1", SourceIds: []string{"a", "b"}}},
+		},
+		{
+			name:  "same isrc with another id",
+			items: []sourceItem{newSourceItem("a", "1", "ISRC1", ""), newSourceItem("b", "2", "ISRC1", "")},
+			want:  []state.MergedTrack{{TrackId: "1", ISRC: "ISRC1", SourceIds: []string{"a", "b"}}},
+		},
+		{
+			name:  "twice in one source",
+			items: []sourceItem{newSourceItem("a", "1", "", ""), newSourceItem("a", "1", "", "")},
+			want:  []state.MergedTrack{{TrackId: "1", SourceIds: []string{"a"}}},
+		},
+		{
+			name:  "empty",
+			items: []sourceItem{},
+			want:  []state.MergedTrack{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, tracks := mergeItems(test.items)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("mergeItems() = %v, want %v", got, test.want)
+			}
+			if len(tracks) != len(got) {
+				t.Errorf("mergeItems() returned %d tracks for %d merged tracks", len(tracks), len(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package syncer
+
+import (
+	"api/internal/configuration"
+	"api/internal/provider"
+	"api/internal/state"
+	"errors"
+	"time"
+)
+
+// SyncPair syncs the playlist of a pair from its source to its destination. Only the providers are used, so any
+// source can be combined with any destination.
+func SyncPair(pairId string) (SyncResult, error) {
+	logger := getLogger()
+	logger.Debug("going to sync pair: " + pairId)
+
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return SyncResult{}, err
+	}
+	pairConfig, ok := config.Pairs[pairId]
+	if !ok {
+		return SyncResult{}, errors.New("unknown pair: " + pairId)
+	}
+
+	return syncPair(pairId, pairConfig)
+}
+
+// syncPlaylistDestinations syncs a Spotify playlist to the extra destinations of its configuration, with the same
+// mode as the Apple Music playlist. Each destination is synced as a pair with its own state. Destinations that can not
+// be read from are appended to instead of synced both ways.
+func syncPlaylistDestinations(playlistId string, playlistConfig configuration.PlaylistConfig) []SyncResult {
+	logger := getLogger()
+	results := make([]SyncResult, 0)
+	mode, modeErr := getPlaylistMode(playlistId, playlistConfig)
+	for _, destination := range playlistConfig.Destinations {
+		pairId := destination + ":" + playlistId
+		destinationMode := mode
+		if destinationMode == configuration.ModeTwoWay {
+			if _, sourceErr := provider.GetSource(destination); sourceErr != nil {
+				logger.Info("syncing playlistId '", playlistId, "' to ", destination, " in append mode, two-way sync is not supported for it")
+				destinationMode = configuration.ModeAppend
+			}
+		}
+		result, err := SyncResult{}, modeErr
+		if err == nil {
+			result, err = syncPair(pairId, configuration.PairConfig{
+				Source:         provider.Spotify,
+				Destination:    destination,
+				PlaylistId:     playlistId,
+				KeepDuplicates: playlistConfig.KeepDuplicates,
+				Mode:           destinationMode,
+				ConflictPolicy: playlistConfig.ConflictPolicy,
+				Archive:        playlistConfig.Archive,
+				Filters:        playlistConfig.Filters,
+			})
+		}
+		if err != nil {
+			logger.Error("error while syncing playlistId '", playlistId, "' to ", destination, ": ", err.Error())
+			result.PlaylistId = pairId
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func syncPair(pairId string, pairConfig configuration.PairConfig) (SyncResult, error) {
+	return pairSync{id: pairId, config: pairConfig}.run()
+}
+
+// pairSync is a playlist of a source that is synced into a destination with one of the modes
+type pairSync struct {
+	id     string
+	config configuration.PairConfig
+
+	// libraryTarget adds the tracks to the library of the destination instead of a playlist, alsoAddToLibrary adds
+	// them to both
+	libraryTarget    bool
+	alsoAddToLibrary bool
+
+	// inPlaylists keeps the progress with the playlists of the state instead of the pairs, where the Spotify
+	// playlists that are synced to Apple Music have always been kept
+	inPlaylists bool
+}
+
+// pairRun is a pairSync while it is running
+type pairRun struct {
+	pairSync
+	source         provider.Source
+	destination    provider.Destination
+	sourcePlaylist *provider.Playlist
+	stateObj       *state.State
+	syncStart      time.Time
+}
+
+func (p pairSync) run() (SyncResult, error) {
+	logger := getLogger()
+	source, err := provider.GetSource(p.config.Source)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	destination, err := provider.GetDestination(p.config.Destination)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	stateObj, err := state.GetState()
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	stateObj.Syncing = true
+	err = state.SaveState(stateObj)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	SendToAll(StatusMessage{Syncing: true})
+
+	defer func() {
+		stateObj.Syncing = false
+		_ = state.SaveState(stateObj)
+	}()
+
+	sourcePlaylist, err := source.GetPlaylist(p.config.PlaylistId)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	logger.Debug("found source playlist: " + sourcePlaylist.Name)
+
+	r := &pairRun{
+		pairSync:       p,
+		source:         source,
+		destination:    destination,
+		sourcePlaylist: sourcePlaylist,
+		stateObj:       &stateObj,
+		syncStart:      time.Now(),
+	}
+
+	allTracks, skippedTracks, err := source.GetPlaylistTracks(p.config.PlaylistId)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	logger.Debug("got tracks. amount: ", len(allTracks))
+	if len(skippedTracks) > 0 {
+		logger.Info("skipped tracks that can not be synced. amount: ", len(skippedTracks))
+	}
+
+	tracks, filteredTracks := filterTracks(allTracks, p.config.Filters)
+	if len(filteredTracks) > 0 {
+		logger.Info("filtered tracks. amount: ", len(filteredTracks))
+	}
+
+	playlistState := r.getState()
+	playlistState.SkippedTracks = skippedTracks
+	playlistState.FilteredTracks = filteredTracks
+	r.getStates()[p.id] = playlistState
+
+	var result SyncResult
+	switch p.config.Mode {
+	case configuration.ModeArchive:
+		result, err = r.syncArchive(tracks)
+	case configuration.ModeReplace:
+		result, err = r.syncReplace(tracks)
+	case configuration.ModeTwoWay:
+		result, err = r.syncTwoWay(tracks, allTracks)
+	default:
+		result, err = r.syncAppend(tracks)
+	}
+	result.PlaylistId = p.id
+	result.Filtered = len(filteredTracks)
+	result.Skipped = len(skippedTracks)
+	if err != nil {
+		return result, err
+	}
+
+	if result.IsPartial() {
+		logger.Warn("playlist partially synced. failed tracks: ", result.Failed)
+	}
+
+	SendToAll(StatusMessage{Syncing: false, Result: &result})
+	return result, nil
+}
+
+// syncAppend adds the tracks that were added to the source since the previous sync. Tracks without a date are
+// searched until they are found, their matches are remembered so they are only searched once.
+func (r *pairRun) syncAppend(tracks []provider.Track) (SyncResult, error) {
+	logger := getLogger()
+	existingTrackIds := make([]string, 0)
+	var addTracks func(trackIds []string) error
+	if r.libraryTarget {
+		// Adding songs that are already in the library has no effect, so there is nothing to compare with
+		logger.Debug("syncing into the library of the destination")
+		addTracks = r.addToLibrary
+	} else {
+		destinationPlaylist, trackIds, err := r.getDestinationPlaylist()
+		if err != nil {
+			return SyncResult{}, err
+		}
+		existingTrackIds = trackIds
+
+		destinationPlaylistId := destinationPlaylist.Id
+		addTracks = func(trackIds []string) error {
+			// The library goes first, adding the same songs again when the playlist fails is harmless
+			if r.alsoAddToLibrary {
+				err := r.addToLibrary(trackIds)
+				if err != nil {
+					return err
+				}
+			}
+			return r.destination.AddTracks(destinationPlaylistId, trackIds)
+		}
+	}
+
+	playlistState := r.getState()
+	if playlistState.TrackIds == nil {
+		playlistState.TrackIds = make(map[string]string)
+	}
+
+	// Tracks that failed during an earlier sync are searched again, regardless of when they were added
+	retryTrackIds := make(map[string]bool)
+	for _, failedTrack := range playlistState.FailedTracks {
+		retryTrackIds[failedTrack.TrackId] = true
+	}
+
+	knownTrackIds := make([]string, 0)
+	searchTracks := make([]provider.Track, 0)
+	for _, track := range tracks {
+		switch {
+		case track.AddedAt.IsZero() && playlistState.TrackIds[track.Id] != "":
+			knownTrackIds = append(knownTrackIds, playlistState.TrackIds[track.Id])
+		case track.AddedAt.IsZero() || track.AddedAt.After(playlistState.LastSyncDate) || retryTrackIds[track.Id]:
+			searchTracks = append(searchTracks, track)
+		default:
+			logger.Debug("skipped ", track.ArtistNames(), " - "+track.Name+" because of addedAt")
+		}
+	}
+
+	trackIds, failedTracks, unmatchedTracks, err := findTrackIds(r.destination, searchTracks)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	matchedTrackIds := make([]string, 0)
+	for i, trackId := range trackIds {
+		if trackId == "" {
+			continue
+		}
+		matchedTrackIds = append(matchedTrackIds, trackId)
+		if searchTracks[i].AddedAt.IsZero() {
+			playlistState.TrackIds[searchTracks[i].Id] = trackId
+		}
+	}
+	matched := len(matchedTrackIds)
+
+	// Tracks that were matched during an earlier sync but never added go first
+	addTrackIds := make([]string, 0)
+	addTrackIds = append(addTrackIds, playlistState.PendingTrackIds...)
+	addTrackIds = append(addTrackIds, knownTrackIds...)
+	addTrackIds = append(addTrackIds, matchedTrackIds...)
+	addTrackIds, duplicates := removeDuplicates(addTrackIds, existingTrackIds, r.config.KeepDuplicates)
+	if duplicates > 0 {
+		logger.Info("skipped duplicate tracks. amount: ", duplicates)
+	}
+
+	// Everything up to the start of this sync has been matched, what is left to do is tracked as pending
+	playlistState.LastSyncDate = r.syncStart
+	playlistState.SkippedDuplicates = duplicates
+	playlistState.PendingTrackIds = addTrackIds
+	playlistState.FailedTracks = failedTracks
+	playlistState.UnmatchedTracks = unmatchedTracks
+	err = r.saveState(playlistState)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	result := SyncResult{
+		Matched:    matched,
+		Unmatched:  len(unmatchedTracks),
+		Failed:     len(failedTracks),
+		Duplicates: duplicates,
+	}
+
+	result.Added, err = addTracksInBatches(addTrackIds, addTracks, func(remaining []string) error {
+		playlistState.PendingTrackIds = remaining
+		return r.saveState(playlistState)
+	})
+	return result, err
+}
+
+// getDestinationPlaylist returns the playlist the source playlist is synced to, with the ids of its tracks. The
+// playlist is created when it does not exist yet, its id is saved right away so it is not created twice.
+func (r *pairRun) getDestinationPlaylist() (*provider.Playlist, []string, error) {
+	logger := getLogger()
+	playlistState := r.getState()
+	destinationPlaylist, err := r.destination.GetSyncedPlaylist(r.config.PlaylistId, playlistState.PlaylistId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if destinationPlaylist != nil {
+		trackIds, err := r.destination.GetPlaylistTrackIds(destinationPlaylist.Id)
+		if err != nil {
+			return nil, nil, err
+		}
+		logger.Debug("got existing tracks of the destination playlist. amount: ", len(trackIds))
+		playlistState.PlaylistId = destinationPlaylist.Id
+		r.getStates()[r.id] = playlistState
+		return destinationPlaylist, trackIds, nil
+	}
+
+	destinationPlaylist, err = r.destination.CreatePlaylist(r.sourcePlaylist.Name, r.config.PlaylistId)
+	if err != nil {
+		return nil, nil, err
+	}
+	logger.Debug("created playlist with name: " + destinationPlaylist.Name)
+
+	playlistState.PlaylistId = destinationPlaylist.Id
+	err = r.saveState(playlistState)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return destinationPlaylist, make([]string, 0), nil
+}
+
+// addToLibrary adds the tracks to the library of the destination and remembers them
+func (r *pairRun) addToLibrary(trackIds []string) error {
+	library, ok := r.destination.(provider.Library)
+	if !ok {
+		return errors.New(r.config.Destination + " has no library to add tracks to")
+	}
+
+	err := library.AddToLibrary(trackIds)
+	if err != nil {
+		return err
+	}
+	recordLibrarySongs(r.stateObj, trackIds)
+	return nil
+}
+
+func (r *pairRun) getState() state.PlaylistState {
+	return r.getStates()[r.id]
+}
+
+// saveState stores the progress of the sync and saves the whole state
+func (r *pairRun) saveState(playlistState state.PlaylistState) error {
+	r.getStates()[r.id] = playlistState
+	return state.SaveState(*r.stateObj)
+}
+
+func (r *pairRun) getStates() map[string]state.PlaylistState {
+	if r.inPlaylists {
+		if r.stateObj.Playlists == nil {
+			r.stateObj.Playlists = make(map[string]state.PlaylistState)
+		}
+		return r.stateObj.Playlists
+	}
+
+	if r.stateObj.Pairs == nil {
+		r.stateObj.Pairs = make(map[string]state.PlaylistState)
+	}
+	return r.stateObj.Pairs
+}
@@ -0,0 +1,105 @@
+package syncer
+
+import (
+	"api/internal/provider"
+	"errors"
+	"time"
+)
+
+// syncReplace makes the destination playlist contain exactly the tracks of the source playlist. Destination ids of
+// earlier syncs are remembered, so only tracks that were never seen before are searched.
+func (r *pairRun) syncReplace(tracks []provider.Track) (SyncResult, error) {
+	logger := getLogger()
+	result := SyncResult{}
+
+	playlistState := r.getState()
+	if playlistState.TrackIds == nil {
+		playlistState.TrackIds = make(map[string]string)
+	}
+
+	searchTracks := make([]provider.Track, 0)
+	for _, track := range tracks {
+		if _, known := playlistState.TrackIds[track.Id]; !known {
+			searchTracks = append(searchTracks, track)
+		}
+	}
+
+	trackIds, failedTracks, unmatchedTracks, err := findTrackIds(r.destination, searchTracks)
+	if err != nil {
+		return result, err
+	}
+	// Only matches are remembered, unmatched and failed tracks are searched again during the next sync
+	for i, trackId := range trackIds {
+		if trackId != "" {
+			playlistState.TrackIds[searchTracks[i].Id] = trackId
+		}
+	}
+
+	desiredTrackIds := make([]string, 0)
+	for _, track := range tracks {
+		if trackId := playlistState.TrackIds[track.Id]; trackId != "" {
+			desiredTrackIds = append(desiredTrackIds, trackId)
+		}
+	}
+	result.Matched = len(desiredTrackIds)
+	desiredTrackIds, result.Duplicates = removeDuplicates(desiredTrackIds, nil, r.config.KeepDuplicates)
+
+	destinationPlaylist, existingTrackIds, err := r.getDestinationPlaylist()
+	if err != nil {
+		return result, err
+	}
+	playlistState.PlaylistId = destinationPlaylist.Id
+
+	desired := make(map[string]bool)
+	for _, trackId := range desiredTrackIds {
+		desired[trackId] = true
+	}
+	removedTrackIds := make([]string, 0)
+	for _, trackId := range existingTrackIds {
+		if !desired[trackId] {
+			removedTrackIds = append(removedTrackIds, trackId)
+		}
+	}
+
+	// A failed removal does not stop the new tracks from being added, it is returned once they are
+	var removeErr error
+	if len(removedTrackIds) > 0 {
+		removeErr = r.destination.RemoveTracks(destinationPlaylist.Id, removedTrackIds)
+		if removeErr != nil {
+			logger.Warn("could not remove tracks from playlist: ", removeErr.Error())
+		} else {
+			result.Removed = len(removedTrackIds)
+		}
+	}
+
+	addTrackIds, _ := removeDuplicates(desiredTrackIds, existingTrackIds, true)
+
+	playlistState.LastSyncDate = time.Now()
+	playlistState.SkippedDuplicates = result.Duplicates
+	playlistState.FailedTracks = failedTracks
+	playlistState.UnmatchedTracks = unmatchedTracks
+	playlistState.PendingTrackIds = addTrackIds
+	err = r.saveState(playlistState)
+	if err != nil {
+		return result, err
+	}
+
+	result.Unmatched = len(unmatchedTracks)
+	result.Failed = len(failedTracks)
+
+	destinationPlaylistId := destinationPlaylist.Id
+	result.Added, err = addTracksInBatches(addTrackIds, func(trackIds []string) error {
+		return r.destination.AddTracks(destinationPlaylistId, trackIds)
+	}, func(remaining []string) error {
+		playlistState.PendingTrackIds = remaining
+		return r.saveState(playlistState)
+	})
+	if err != nil {
+		return result, err
+	}
+	if removeErr != nil {
+		return result, errors.New("could not remove tracks from playlist: " + removeErr.Error())
+	}
+
+	return result, nil
+}
@@ -0,0 +1,41 @@
+package syncer
+
+import (
+	"api/internal/provider"
+	"api/internal/state"
+)
+
+type SyncResult struct {
+	PlaylistId string `json:"playlist-id"`
+	Matched    int    `json:"matched"`
+	Added      int    `json:"added"`
+	Removed    int    `json:"removed"`
+	Unmatched  int    `json:"unmatched"`
+	Filtered   int    `json:"filtered"`
+	Skipped    int    `json:"skipped"`
+	Failed     int    `json:"failed"`
+	Duplicates int    `json:"duplicates"`
+	Error      string `json:"error,omitempty"`
+
+	// Warnings are problems that did not stop the sync, like archives that could not be deleted
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Two-way syncs also change the source playlist
+	AddedToSource     int `json:"added-to-source,omitempty"`
+	RemovedFromSource int `json:"removed-from-source,omitempty"`
+}
+
+// IsPartial reports whether some tracks could not be synced because of an error. These tracks are retried
+// during the next sync.
+func (r SyncResult) IsPartial() bool {
+	return r.Failed > 0
+}
+
+func newReport(track provider.Track, reason string) state.TrackReport {
+	return state.TrackReport{
+		TrackId: track.Id,
+		Name:    track.Name,
+		Artists: track.ArtistNames(),
+		Reason:  reason,
+	}
+}
@@ -0,0 +1,24 @@
+package syncer
+
+import (
+	"api/internal/configuration"
+	"api/internal/provider"
+)
+
+// SyncAppleMusicPlaylist adds the tracks of an Apple Music library playlist to a Spotify playlist, which is
+// created during the first sync. It is synced like a pair with Apple Music as the source.
+func SyncAppleMusicPlaylist(playlistId string) (SyncResult, error) {
+	logger := getLogger()
+	logger.Debug("going to sync Apple Music playlist: " + playlistId)
+
+	result, err := pairSync{
+		id: provider.Spotify + ":" + playlistId,
+		config: configuration.PairConfig{
+			Source:      provider.AppleMusic,
+			Destination: provider.Spotify,
+			PlaylistId:  playlistId,
+		},
+	}.run()
+	result.PlaylistId = playlistId
+	return result, err
+}
@@ -0,0 +1,113 @@
+package syncer
+
+import (
+	"api/internal/configuration"
+	"api/internal/spotify"
+	"api/internal/state"
+	"errors"
+	spotifylib "github.com/zmb3/spotify/v2"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SelectPlaylists evaluates the selection rules against the playlists of the user. The ids of all playlists of the
+// user are returned as well, they are nil when there are no rules.
+func SelectPlaylists(selection configuration.SelectionConfig) ([]string, map[string]bool, error) {
+	logger := getLogger()
+	if len(selection.Include) == 0 {
+		return make([]string, 0), nil, nil
+	}
+
+	me, err := spotify.GetMe()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	playlists, err := spotify.GetUserPlaylists()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	existing := make(map[string]bool)
+	selectedIds := make([]string, 0)
+	for _, playlist := range playlists {
+		existing[playlist.ID.String()] = true
+		// Playlists that spync created on Spotify would be synced back again
+		if spotify.IsSyncedPlaylist(playlist.Description) {
+			continue
+		}
+		if matchesAnyRule(playlist, me.ID, selection.Include) && !matchesAnyRule(playlist, me.ID, selection.Exclude) {
+			selectedIds = append(selectedIds, playlist.ID.String())
+		}
+	}
+	logger.Debug("selected playlists by rules. amount: ", len(selectedIds))
+
+	return selectedIds, existing, nil
+}
+
+// recordSelection stores the selected playlists in the state. Playlists that were selected during an earlier run but
+// no longer exist are flagged as deleted, the flag is cleared when they exist again.
+func recordSelection(selectedIds []string, existing map[string]bool) error {
+	logger := getLogger()
+	stateObj, err := state.GetState()
+	if err != nil {
+		return err
+	}
+
+	if stateObj.Playlists == nil {
+		stateObj.Playlists = make(map[string]state.PlaylistState)
+	}
+	for _, playlistId := range stateObj.SelectedPlaylistIds {
+		if !existing[playlistId] {
+			logger.Warn("selected playlist no longer exists: ", playlistId)
+			playlistSyncState := stateObj.Playlists[playlistId]
+			if playlistSyncState.DeletedDate == nil {
+				now := time.Now()
+				playlistSyncState.DeletedDate = &now
+			}
+			stateObj.Playlists[playlistId] = playlistSyncState
+		}
+	}
+	for playlistId, playlistSyncState := range stateObj.Playlists {
+		if playlistSyncState.DeletedDate != nil && existing[playlistId] {
+			logger.Info("deleted playlist exists again: ", playlistId)
+			playlistSyncState.DeletedDate = nil
+			stateObj.Playlists[playlistId] = playlistSyncState
+		}
+	}
+	stateObj.SelectedPlaylistIds = selectedIds
+
+	return state.SaveState(stateObj)
+}
+
+func matchesAnyRule(playlist spotifylib.SimplePlaylist, userId string, rules []configuration.SelectionRule) bool {
+	logger := getLogger()
+	for _, rule := range rules {
+		matches, err := matchesRule(playlist, userId, rule)
+		if err != nil {
+			logger.Warn("invalid selection rule '", rule.Type, "': ", err.Error())
+		} else if matches {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesRule(playlist spotifylib.SimplePlaylist, userId string, rule configuration.SelectionRule) (bool, error) {
+	switch rule.Type {
+	case configuration.RuleOwned:
+		return playlist.Owner.ID == userId, nil
+	case configuration.RuleCollaborative:
+		return playlist.Collaborative, nil
+	case configuration.RuleNameGlob:
+		return path.Match(strings.ToLower(rule.Value), strings.ToLower(playlist.Name))
+	case configuration.RuleNameRegex:
+		return regexp.MatchString(rule.Value, playlist.Name)
+	case configuration.RuleOwner:
+		return playlist.Owner.ID == rule.Value || playlist.Owner.DisplayName == rule.Value, nil
+	default:
+		return false, errors.New("unknown rule type")
+	}
+}
@@ -14,7 +14,8 @@ type Connection struct {
 }
 
 type StatusMessage struct {
-	Syncing bool `json:"syncing"`
+	Syncing bool        `json:"syncing"`
+	Result  *SyncResult `json:"result,omitempty"`
 }
 
 func (c *Connection) Send(message StatusMessage) error {
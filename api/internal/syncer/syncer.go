@@ -1,127 +1,252 @@
 package syncer
 
 import (
-	"api/internal/applemusic"
 	"api/internal/configuration"
+	"api/internal/local"
+	"api/internal/provider"
 	"api/internal/spotify"
 	"api/internal/state"
+	"errors"
 	"go.uber.org/zap"
-	"time"
+	"sort"
 )
 
-func SyncPlaylist(playlistId string) error {
+// SyncPlaylist syncs a Spotify playlist to Apple Music with the mode of its configuration
+func SyncPlaylist(playlistId string) (SyncResult, error) {
 	logger := getLogger()
 	logger.Debug("going to sync: " + playlistId)
 
-	logger.Debug("set syncing status")
-	stateObj, err := state.GetState()
+	config, err := configuration.GetConfiguration()
 	if err != nil {
-		return err
+		return SyncResult{}, err
 	}
-
-	stateObj.Syncing = true
-	err = state.SaveState(stateObj)
+	playlistConfig := config.GetPlaylistConfig(playlistId)
+	mode, err := getPlaylistMode(playlistId, playlistConfig)
 	if err != nil {
-		return err
+		return SyncResult{PlaylistId: playlistId}, err
 	}
 
-	SendToAll(StatusMessage{Syncing: true})
-
-	defer func(finalState state.State) {
-		finalState.Syncing = false
-		_ = state.SaveState(finalState)
-	}(stateObj)
+	return pairSync{
+		id: playlistId,
+		config: configuration.PairConfig{
+			Source:         provider.Spotify,
+			Destination:    provider.AppleMusic,
+			PlaylistId:     playlistId,
+			KeepDuplicates: playlistConfig.KeepDuplicates,
+			Mode:           mode,
+			ConflictPolicy: playlistConfig.ConflictPolicy,
+			Archive:        playlistConfig.Archive,
+			Filters:        playlistConfig.Filters,
+		},
+		libraryTarget:    playlistConfig.Target == configuration.TargetLibrary,
+		alsoAddToLibrary: config.ShouldAddToLibrary(playlistId),
+		inPlaylists:      true,
+	}.run()
+}
 
-	spotifyPlaylist, err := spotify.GetPlaylist(playlistId)
-	if err != nil {
-		return err
+// getPlaylistMode returns the mode a Spotify playlist is synced with. Playlists generated from the listening history
+// change completely, so they are replaced by default.
+func getPlaylistMode(playlistId string, playlistConfig configuration.PlaylistConfig) (string, error) {
+	mode := playlistConfig.Mode
+	if mode == "" && spotify.IsGeneratedPlaylist(playlistId) {
+		mode = configuration.ModeReplace
 	}
-	logger.Debug("found spotify playlist: " + spotifyPlaylist.Name)
+	if mode == configuration.ModeTwoWay && spotify.IsPseudoPlaylist(playlistId) {
+		return "", errors.New("two-way sync is only supported for Spotify playlists")
+	}
+	return mode, nil
+}
 
-	logger.Debug("going to retrieve tracks")
-	tracks, err := spotify.GetPlaylistTracks(playlistId)
+func SyncAllPlaylists() []SyncResult {
+	logger := getLogger()
+	config, _ := configuration.GetConfiguration()
+	playlistIds, err := getPlaylistIdsToSync(config, true)
 	if err != nil {
-		return err
+		logger.Error("error while selecting playlists, only syncing configured playlists: ", err.Error())
+		playlistIds = config.Spotify.PlaylistIds
+	}
+	results := make([]SyncResult, 0)
+	for _, playlistId := range playlistIds {
+		result, err := SyncPlaylist(playlistId)
+		if err != nil {
+			logger.Error("error while syncing playlistId '", playlistId, "' : ", err.Error())
+			result.PlaylistId = playlistId
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+		results = append(results, syncPlaylistDestinations(playlistId, config.GetPlaylistConfig(playlistId))...)
 	}
-	logger.Debug("got tracks. amount: ", len(tracks))
 
-	applemusicPlaylist, err := applemusic.GetSyncedPlaylist(playlistId)
-	if err != nil {
-		return err
+	targetIds := make([]string, 0)
+	for targetId := range config.Targets {
+		targetIds = append(targetIds, targetId)
+	}
+	sort.Strings(targetIds)
+	for _, targetId := range targetIds {
+		result, err := SyncTarget(targetId)
+		if err != nil {
+			logger.Error("error while syncing target '", targetId, "' : ", err.Error())
+			result.PlaylistId = targetId
+			result.Error = err.Error()
+		}
+		results = append(results, result)
 	}
 
-	if applemusicPlaylist == nil {
-		// Create the AM playlist
-		applemusicPlaylist, err = applemusic.CreatePlaylist(spotifyPlaylist.Name, playlistId)
+	for _, playlistId := range config.AppleMusic.PlaylistIds {
+		result, err := SyncAppleMusicPlaylist(playlistId)
 		if err != nil {
-			return err
+			logger.Error("error while syncing Apple Music playlistId '", playlistId, "' : ", err.Error())
+			result.PlaylistId = playlistId
+			result.Error = err.Error()
 		}
-		logger.Debug("created Apple Music playlist with name: " + applemusicPlaylist.Attributes.Name)
-	} else {
-		logger.Debug("playlist already exists")
-	}
-
-	// Find each Spotify track on AM
-	playlistSyncState := stateObj.Playlists[playlistId]
-	lastPlaylistSyncDate := playlistSyncState.LastSyncDate
-
-	applemusicTrackIds := make([]string, 0)
-	logger.Debug("going to search for tracks on Apple Music")
-	for _, spotifyTrack := range tracks {
-		addedAt, _ := time.Parse(time.RFC3339, spotifyTrack.AddedAt)
-		track := spotifyTrack.Track.Track
-		if addedAt.After(lastPlaylistSyncDate) {
-			logger.Debug("searching for ", spotify.GetArtistNames(track), " - "+track.Name)
-			amTrack, err := applemusic.FindTrack(track)
-			if err != nil {
-				return err
-			} else if amTrack != nil {
-				logger.Debug("found track on Apple Music: ", amTrack.Attributes.ArtistName, " - ", amTrack.Attributes.Name)
-				applemusicTrackIds = append(applemusicTrackIds, amTrack.Id)
-			} else if amTrack == nil {
-				logger.Warn("could not find track: " + track.Name)
-			}
-			logger.Debug("----------------------------------------------------------------------")
-		} else {
-			logger.Debug("skipped ", spotify.GetArtistNames(track), " - "+track.Name+" because of addedAt")
+		results = append(results, result)
+	}
+
+	var library *local.Library
+	var scanErr error
+	if len(config.Local.PlaylistIds) > 0 {
+		library, scanErr = scanLocalLibrary()
+	}
+	for _, playlistId := range config.Local.PlaylistIds {
+		result, err := SyncResult{}, scanErr
+		if err == nil {
+			result, err = syncLocalPlaylist(playlistId, library)
 		}
+		if err != nil {
+			logger.Error("error while syncing playlistId '", playlistId, "' to local files: ", err.Error())
+			result.PlaylistId = playlistId
+			result.Error = err.Error()
+		}
+		results = append(results, result)
 	}
-	logger.Debug("got Apple Music tracks. Amount: ", len(applemusicTrackIds))
 
-	if len(applemusicTrackIds) > 0 {
-		err = applemusic.AddTracksToPlaylist(applemusicPlaylist.Id, applemusicTrackIds)
+	pairIds := make([]string, 0)
+	for pairId := range config.Pairs {
+		pairIds = append(pairIds, pairId)
+	}
+	sort.Strings(pairIds)
+	for _, pairId := range pairIds {
+		result, err := SyncPair(pairId)
 		if err != nil {
-			return err
+			logger.Error("error while syncing pair '", pairId, "' : ", err.Error())
+			result.PlaylistId = pairId
+			result.Error = err.Error()
 		}
-		logger.Debug("added tracks to playlist. amount: ", len(applemusicTrackIds))
+		results = append(results, result)
 	}
 
-	if stateObj.Playlists == nil {
-		stateObj.Playlists = make(map[string]state.PlaylistState)
+	if config.Spotify.SyncSavedAlbums {
+		result, err := SyncSavedAlbums()
+		if err != nil {
+			logger.Error("error while syncing saved albums: ", err.Error())
+			result.PlaylistId = spotify.SavedAlbumsId
+			result.Error = err.Error()
+		}
+		results = append(results, result)
 	}
+	return results
+}
+
+// GetPlaylistIdsToSync combines the configured playlists and sources with the playlists that are selected by the
+// rules
+func GetPlaylistIdsToSync(config configuration.Config) ([]string, error) {
+	return getPlaylistIdsToSync(config, false)
+}
 
-	stateObj.Playlists[playlistId] = state.PlaylistState{LastSyncDate: time.Now()}
-	err = state.SaveState(stateObj)
+// getPlaylistIdsToSync is GetPlaylistIdsToSync that also records the selection in the state when record is set, which
+// is only done by syncs
+func getPlaylistIdsToSync(config configuration.Config, record bool) ([]string, error) {
+	selectedIds, existing, err := SelectPlaylists(config.Spotify.Selection)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if record && existing != nil {
+		err = recordSelection(selectedIds, existing)
+		if err != nil {
+			return nil, err
+		}
 	}
-	logger.Debug("setting last sync date to: ", time.Now())
 
-	SendToAll(StatusMessage{Syncing: false})
+	added := make(map[string]bool)
+	playlistIds := make([]string, 0)
+	candidateIds := make([]string, 0, len(config.Spotify.PlaylistIds)+len(config.Spotify.Sources)+len(selectedIds))
+	candidateIds = append(candidateIds, config.Spotify.PlaylistIds...)
+	candidateIds = append(candidateIds, config.Spotify.Sources...)
+	candidateIds = append(candidateIds, selectedIds...)
+	for _, playlistId := range candidateIds {
+		if !added[playlistId] {
+			added[playlistId] = true
+			playlistIds = append(playlistIds, playlistId)
+		}
+	}
 
-	return nil
+	return playlistIds, nil
 }
 
-func SyncAllPlaylists() {
+// addTracksInBatches adds the tracks to the destination in batches. After every batch the remaining tracks are passed
+// to saveProgress, so a failing batch leaves only the remaining tracks to be retried.
+func addTracksInBatches(trackIds []string, addTracks func(trackIds []string) error, saveProgress func(remaining []string) error) (int, error) {
 	logger := getLogger()
-	config, _ := configuration.GetConfiguration()
-	playlistIds := config.Spotify.PlaylistIds
-	for _, playlistId := range playlistIds {
-		err := SyncPlaylist(playlistId)
+	added := 0
+	for len(trackIds) > 0 {
+		batchSize := provider.MaxTracksPerRequest
+		if len(trackIds) < batchSize {
+			batchSize = len(trackIds)
+		}
+
+		err := addTracks(trackIds[:batchSize])
 		if err != nil {
-			logger.Error("error while syncing playlistId '", playlistId, "' : ", err.Error())
+			return added, err
+		}
+		added += batchSize
+		trackIds = trackIds[batchSize:]
+
+		if saveProgress != nil {
+			err = saveProgress(trackIds)
+			if err != nil {
+				return added, err
+			}
 		}
+		logger.Debug("added tracks. amount: ", batchSize, ", remaining: ", len(trackIds))
 	}
+
+	return added, nil
+}
+
+// recordLibrarySongs keeps track of the songs spync added to the Apple Music library, so they can be cleaned up later
+func recordLibrarySongs(stateObj *state.State, trackIds []string) {
+	recorded := make(map[string]bool)
+	for _, trackId := range stateObj.AppleMusic.LibrarySongIds {
+		recorded[trackId] = true
+	}
+
+	for _, trackId := range trackIds {
+		if !recorded[trackId] {
+			recorded[trackId] = true
+			stateObj.AppleMusic.LibrarySongIds = append(stateObj.AppleMusic.LibrarySongIds, trackId)
+		}
+	}
+}
+
+// removeDuplicates drops tracks that are already in the destination playlist. Tracks that occur more than once in
+// the source playlist are collapsed into one, unless keepDuplicates is set.
+func removeDuplicates(trackIds []string, existingTrackIds []string, keepDuplicates bool) ([]string, int) {
+	existing := make(map[string]bool)
+	for _, trackId := range existingTrackIds {
+		existing[trackId] = true
+	}
+
+	added := make(map[string]bool)
+	result := make([]string, 0)
+	for _, trackId := range trackIds {
+		if existing[trackId] || (added[trackId] && !keepDuplicates) {
+			continue
+		}
+		added[trackId] = true
+		result = append(result, trackId)
+	}
+
+	return result, len(trackIds) - len(result)
 }
 
 func getLogger() *zap.SugaredLogger {
@@ -0,0 +1,290 @@
+package syncer
+
+import (
+	"api/internal/configuration"
+	"api/internal/provider"
+	"errors"
+	"time"
+)
+
+// syncTwoWay propagates additions on both sides of a playlist pair. The mapping of the previous sync holds the tracks
+// that were on both sides, so a track missing from one side was removed there and is not a new track on the other.
+// Only the tracks are synced to the destination, allTracks are all tracks of the source playlist, including the
+// filtered ones, so tracks that are filtered are not taken for removed tracks. Local tracks are left out of the
+// mapping, they can not be added to or removed from a playlist.
+func (r *pairRun) syncTwoWay(tracks []provider.Track, allTracks []provider.Track) (SyncResult, error) {
+	logger := getLogger()
+	result := SyncResult{}
+
+	// The source is written to and the destination is read from, so both have to work the other way around as well
+	sourceDestination, err := provider.GetDestination(r.config.Source)
+	if err != nil {
+		return result, errors.New("two-way sync is not supported from " + r.config.Source + ": " + err.Error())
+	}
+	destinationSource, err := provider.GetSource(r.config.Destination)
+	if err != nil {
+		return result, errors.New("two-way sync is not supported to " + r.config.Destination + ": " + err.Error())
+	}
+
+	policy := r.config.ConflictPolicy
+	if policy == "" {
+		policy = configuration.ConflictSourceWins
+	}
+
+	destinationPlaylist, _, err := r.getDestinationPlaylist()
+	if err != nil {
+		return result, err
+	}
+	destinationTracks, skippedTracks, err := destinationSource.GetPlaylistTracks(destinationPlaylist.Id)
+	if err != nil {
+		return result, err
+	}
+
+	playlistState := r.getState()
+	lastSyncDate := playlistState.LastSyncDate
+	mapping := playlistState.Mapping
+	if mapping == nil {
+		mapping = make(map[string]string)
+	}
+
+	// The date each track was added, to compare with the previous sync
+	onSource := make(map[string]time.Time)
+	localIds := make(map[string]bool)
+	for _, track := range allTracks {
+		if track.IsLocal {
+			localIds[track.Id] = true
+		} else {
+			onSource[track.Id] = track.AddedAt
+		}
+	}
+	// Tracks that are skipped can not be synced, but they are still in the playlist
+	for _, skippedTrack := range playlistState.SkippedTracks {
+		if _, ok := onSource[skippedTrack.TrackId]; !ok && skippedTrack.TrackId != "" {
+			onSource[skippedTrack.TrackId] = time.Time{}
+		}
+	}
+	onDestination := make(map[string]bool)
+	for _, track := range destinationTracks {
+		onDestination[track.Id] = true
+	}
+	for _, skippedTrack := range skippedTracks {
+		onDestination[skippedTrack.TrackId] = true
+	}
+
+	for sourceId := range mapping {
+		if localIds[sourceId] {
+			delete(mapping, sourceId)
+		}
+	}
+
+	// Tracks that are missing on one side since the previous sync are resolved with the policy
+	changes := resolveConflicts(mapping, onSource, onDestination, policy, lastSyncDate)
+	addToSource := changes.addToSource
+	addToDestination := changes.addToDestination
+	removeFromSource := changes.removeFromSource
+	removeFromDestination := changes.removeFromDestination
+
+	// New source tracks
+	searchTracks := make([]provider.Track, 0)
+	for _, track := range tracks {
+		if track.IsLocal {
+			continue
+		}
+		if _, mapped := mapping[track.Id]; !mapped {
+			searchTracks = append(searchTracks, track)
+		}
+	}
+	trackIds, failedTracks, unmatchedTracks, err := findTrackIds(r.destination, searchTracks)
+	if err != nil {
+		return result, err
+	}
+	for i, destinationId := range trackIds {
+		if destinationId == "" {
+			continue
+		}
+		result.Matched++
+		sourceId := searchTracks[i].Id
+		if onDestination[destinationId] {
+			mapping[sourceId] = destinationId
+		} else {
+			addToDestination[sourceId] = destinationId
+		}
+	}
+
+	// New destination tracks, the tracks that were just matched are on both sides already
+	mapped := make(map[string]bool)
+	for _, destinationId := range mapping {
+		mapped[destinationId] = true
+	}
+	for _, destinationId := range addToDestination {
+		mapped[destinationId] = true
+	}
+	for _, destinationId := range removeFromDestination {
+		mapped[destinationId] = true
+	}
+	newDestinationTracks := make([]provider.Track, 0)
+	for _, track := range destinationTracks {
+		if !mapped[track.Id] {
+			newDestinationTracks = append(newDestinationTracks, track)
+			mapped[track.Id] = true
+		}
+	}
+
+	sourceIds, newFailedTracks, newUnmatchedTracks, err := findTrackIds(sourceDestination, newDestinationTracks)
+	if err != nil {
+		return result, err
+	}
+	failedTracks = append(failedTracks, newFailedTracks...)
+	unmatchedTracks = append(unmatchedTracks, newUnmatchedTracks...)
+	for i, sourceId := range sourceIds {
+		if sourceId == "" {
+			continue
+		}
+		result.Matched++
+		if _, exists := onSource[sourceId]; exists {
+			mapping[sourceId] = newDestinationTracks[i].Id
+		} else {
+			addToSource[sourceId] = newDestinationTracks[i].Id
+		}
+	}
+
+	saveMapping := func() error {
+		playlistState.Mapping = mapping
+		return r.saveState(playlistState)
+	}
+
+	// Apply the changes, the mapping only gets the tracks that are on both sides afterwards
+	if len(removeFromDestination) > 0 {
+		removeIds := make([]string, 0)
+		for _, destinationId := range removeFromDestination {
+			removeIds = append(removeIds, destinationId)
+		}
+		err = r.destination.RemoveTracks(destinationPlaylist.Id, removeIds)
+		if err != nil {
+			// The tracks stay in the mapping, so removing them is tried again
+			logger.Warn("could not remove tracks from destination playlist: ", err.Error())
+			result.Warnings = append(result.Warnings, "could not remove tracks from playlist: "+err.Error())
+		} else {
+			for sourceId := range removeFromDestination {
+				delete(mapping, sourceId)
+			}
+			result.Removed = len(removeIds)
+		}
+	}
+
+	if len(removeFromSource) > 0 {
+		err = sourceDestination.RemoveTracks(r.config.PlaylistId, removeFromSource)
+		if err != nil {
+			return result, err
+		}
+		result.RemovedFromSource = len(removeFromSource)
+	}
+
+	// Added in the order of the destination playlist
+	sourceIdsByDestinationId := make(map[string][]string)
+	for sourceId, destinationId := range addToSource {
+		sourceIdsByDestinationId[destinationId] = append(sourceIdsByDestinationId[destinationId], sourceId)
+	}
+	addSourceIds := make([]string, 0)
+	for _, track := range destinationTracks {
+		addSourceIds = append(addSourceIds, sourceIdsByDestinationId[track.Id]...)
+		delete(sourceIdsByDestinationId, track.Id)
+	}
+	_, err = addTracksInBatches(addSourceIds, func(trackIds []string) error {
+		err := sourceDestination.AddTracks(r.config.PlaylistId, trackIds)
+		if err != nil {
+			return err
+		}
+		for _, sourceId := range trackIds {
+			mapping[sourceId] = addToSource[sourceId]
+		}
+		result.AddedToSource += len(trackIds)
+		return nil
+	}, func([]string) error {
+		return saveMapping()
+	})
+	if err != nil {
+		return result, err
+	}
+
+	destinationIds := make([]string, 0)
+	for _, track := range tracks {
+		if destinationId, ok := addToDestination[track.Id]; ok {
+			destinationIds = append(destinationIds, destinationId)
+		}
+	}
+	destinationIds, result.Duplicates = removeDuplicates(destinationIds, nil, false)
+
+	playlistState.FailedTracks = failedTracks
+	playlistState.UnmatchedTracks = unmatchedTracks
+	result.Unmatched = len(unmatchedTracks)
+	result.Failed = len(failedTracks)
+
+	destinationPlaylistId := destinationPlaylist.Id
+	result.Added, err = addTracksInBatches(destinationIds, func(trackIds []string) error {
+		return r.destination.AddTracks(destinationPlaylistId, trackIds)
+	}, func(remaining []string) error {
+		pending := make(map[string]bool)
+		for _, destinationId := range remaining {
+			pending[destinationId] = true
+		}
+		for sourceId, destinationId := range addToDestination {
+			if !pending[destinationId] {
+				mapping[sourceId] = destinationId
+			}
+		}
+		return saveMapping()
+	})
+	if err != nil {
+		return result, err
+	}
+
+	playlistState.LastSyncDate = r.syncStart
+	return result, saveMapping()
+}
+
+// twoWayChanges are the changes to both sides of a two-way pair, by source id. The tracks to add are mapped to the
+// id of the track on the other side.
+type twoWayChanges struct {
+	addToSource           map[string]string
+	addToDestination      map[string]string
+	removeFromSource      []string
+	removeFromDestination map[string]string
+}
+
+// resolveConflicts resolves the tracks of the mapping that are missing on one side with the policy. onSource has the
+// date each source track was added, onDestination the tracks of the destination. Tracks that are gone from both sides
+// or removed from the source are deleted from the mapping.
+func resolveConflicts(mapping map[string]string, onSource map[string]time.Time, onDestination map[string]bool, policy string, lastSyncDate time.Time) twoWayChanges {
+	changes := twoWayChanges{
+		addToSource:           make(map[string]string),
+		addToDestination:      make(map[string]string),
+		removeFromSource:      make([]string, 0),
+		removeFromDestination: make(map[string]string),
+	}
+	for sourceId, destinationId := range mapping {
+		addedAt, inSource := onSource[sourceId]
+		inDestination := onDestination[destinationId]
+		switch {
+		case inSource && inDestination:
+		case !inSource && !inDestination:
+			delete(mapping, sourceId)
+		case !inSource:
+			if policy == configuration.ConflictUnion {
+				changes.addToSource[sourceId] = destinationId
+			} else {
+				changes.removeFromDestination[sourceId] = destinationId
+			}
+		default:
+			// Destinations have no dates for playlist changes, so the removal only loses when the track was added to
+			// the source again after the previous sync
+			if policy == configuration.ConflictLatest && !addedAt.After(lastSyncDate) {
+				changes.removeFromSource = append(changes.removeFromSource, sourceId)
+				delete(mapping, sourceId)
+			} else {
+				changes.addToDestination[sourceId] = destinationId
+			}
+		}
+	}
+	return changes
+}
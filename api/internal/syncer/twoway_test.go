@@ -0,0 +1,117 @@
+package syncer
+
+import (
+	"api/internal/configuration"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestResolveConflicts(t *testing.T) {
+	lastSyncDate := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	before := lastSyncDate.Add(-time.Hour)
+	after := lastSyncDate.Add(time.Hour)
+
+	tests := []struct {
+		name          string
+		policy        string
+		onSource      map[string]time.Time
+		onDestination map[string]bool
+		want          twoWayChanges
+		wantMapping   map[string]string
+	}{
+		{
+			name:          "on both sides",
+			policy:        configuration.ConflictSourceWins,
+			onSource:      map[string]time.Time{"s1": before},
+			onDestination: map[string]bool{"d1": true},
+			want:          newTwoWayChanges(nil, nil, nil, nil),
+			wantMapping:   map[string]string{"s1": "d1"},
+		},
+		{
+			name:          "gone from both sides",
+			policy:        configuration.ConflictSourceWins,
+			onSource:      map[string]time.Time{},
+			onDestination: map[string]bool{},
+			want:          newTwoWayChanges(nil, nil, nil, nil),
+			wantMapping:   map[string]string{},
+		},
+		{
+			name:          "removed from source, source wins",
+			policy:        configuration.ConflictSourceWins,
+			onSource:      map[string]time.Time{},
+			onDestination: map[string]bool{"d1": true},
+			want:          newTwoWayChanges(nil, nil, nil, map[string]string{"s1": "d1"}),
+			wantMapping:   map[string]string{"s1": "d1"},
+		},
+		{
+			name:          "removed from source, union",
+			policy:        configuration.ConflictUnion,
+			onSource:      map[string]time.Time{},
+			onDestination: map[string]bool{"d1": true},
+			want:          newTwoWayChanges(map[string]string{"s1": "d1"}, nil, nil, nil),
+			wantMapping:   map[string]string{"s1": "d1"},
+		},
+		{
+			name:          "removed from destination, source wins",
+			policy:        configuration.ConflictSourceWins,
+			onSource:      map[string]time.Time{"s1": before},
+			onDestination: map[string]bool{},
+			want:          newTwoWayChanges(nil, map[string]string{"s1": "d1"}, nil, nil),
+			wantMapping:   map[string]string{"s1": "d1"},
+		},
+		{
+			name:          "removed from destination, latest",
+			policy:        configuration.ConflictLatest,
+			onSource:      map[string]time.Time{"s1": before},
+			onDestination: map[string]bool{},
+			want:          newTwoWayChanges(nil, nil, []string{"s1"}, nil),
+			wantMapping:   map[string]string{},
+		},
+		{
+			name:          "removed from destination, added to source again, latest",
+			policy:        configuration.ConflictLatest,
+			onSource:      map[string]time.Time{"s1": after},
+			onDestination: map[string]bool{},
+			want:          newTwoWayChanges(nil, map[string]string{"s1": "d1"}, nil, nil),
+			wantMapping:   map[string]string{"s1": "d1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mapping := map[string]string{"s1": "d1"}
+			got := resolveConflicts(mapping, test.onSource, test.onDestination, test.policy, lastSyncDate)
+			sort.Strings(got.removeFromSource)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("resolveConflicts() = %+v, want %+v", got, test.want)
+			}
+			if !reflect.DeepEqual(mapping, test.wantMapping) {
+				t.Errorf("mapping = %v, want %v", mapping, test.wantMapping)
+			}
+		})
+	}
+}
+
+func newTwoWayChanges(addToSource map[string]string, addToDestination map[string]string, removeFromSource []string, removeFromDestination map[string]string) twoWayChanges {
+	changes := twoWayChanges{
+		addToSource:           addToSource,
+		addToDestination:      addToDestination,
+		removeFromSource:      removeFromSource,
+		removeFromDestination: removeFromDestination,
+	}
+	if changes.addToSource == nil {
+		changes.addToSource = make(map[string]string)
+	}
+	if changes.addToDestination == nil {
+		changes.addToDestination = make(map[string]string)
+	}
+	if changes.removeFromSource == nil {
+		changes.removeFromSource = make([]string, 0)
+	}
+	if changes.removeFromDestination == nil {
+		changes.removeFromDestination = make(map[string]string)
+	}
+	return changes
+}
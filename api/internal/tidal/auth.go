@@ -0,0 +1,169 @@
+package tidal
+
+import (
+	"api/internal/configuration"
+	"api/internal/state"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	authURL  = "https://auth.tidal.com/v1/oauth2/device_authorization"
+	tokenURL = "https://auth.tidal.com/v1/oauth2/token"
+	scopes   = "playlists.read playlists.write search.read"
+)
+
+// DeviceAuth is shown to the user, who confirms the login on another device
+type DeviceAuth struct {
+	UserCode        string `json:"user-code"`
+	VerificationUri string `json:"verification-uri"`
+	ExpiresIn       int    `json:"expires-in"`
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	Error        string `json:"error"`
+}
+
+// StartDeviceAuth starts the device flow. The token is requested in the background until the user confirms the
+// login or the code expires.
+func StartDeviceAuth() (*DeviceAuth, error) {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	if config.Tidal.ClientId == "" {
+		return nil, errors.New("no Tidal client configured")
+	}
+
+	params := url.Values{}
+	params.Set("client_id", config.Tidal.ClientId)
+	params.Set("scope", scopes)
+	resp, err := http.PostForm(authURL, params)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, errors.New("Tidal responded with status " + resp.Status)
+	}
+
+	deviceResp := struct {
+		DeviceCode              string `json:"deviceCode"`
+		UserCode                string `json:"userCode"`
+		VerificationUriComplete string `json:"verificationUriComplete"`
+		ExpiresIn               int    `json:"expiresIn"`
+		Interval                int    `json:"interval"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&deviceResp)
+	if err != nil {
+		return nil, err
+	}
+
+	verificationUri := deviceResp.VerificationUriComplete
+	if !strings.HasPrefix(verificationUri, "http") {
+		verificationUri = "https://" + verificationUri
+	}
+	go pollToken(deviceResp.DeviceCode, deviceResp.Interval, deviceResp.ExpiresIn)
+
+	return &DeviceAuth{
+		UserCode:        deviceResp.UserCode,
+		VerificationUri: verificationUri,
+		ExpiresIn:       deviceResp.ExpiresIn,
+	}, nil
+}
+
+func pollToken(deviceCode string, interval int, expiresIn int) {
+	logger := getLogger()
+	if interval <= 0 {
+		interval = 5
+	}
+	deadline := time.Now().Add(time.Duration(expiresIn) * time.Second)
+	for time.Now().Before(deadline) {
+		time.Sleep(time.Duration(interval) * time.Second)
+
+		token, err := requestToken(deviceCode)
+		if errors.Is(err, errSlowDown) {
+			// Tidal asks to poll less often, the interval grows by 5 seconds like RFC 8628 describes
+			interval += 5
+			continue
+		}
+		if err != nil {
+			logger.Error("error while waiting for Tidal login: ", err.Error())
+			return
+		}
+		if token == nil {
+			continue
+		}
+
+		err = saveToken(token)
+		if err != nil {
+			logger.Error("could not save Tidal login: ", err.Error())
+		}
+		logger.Info("logged in to Tidal")
+		return
+	}
+	logger.Warn("Tidal login expired before it was confirmed")
+}
+
+// errSlowDown is returned by requestToken when it is polled too often
+var errSlowDown = errors.New("slow_down")
+
+// requestToken returns nil while the user has not confirmed the login yet
+func requestToken(deviceCode string) (*tokenResponse, error) {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("client_id", config.Tidal.ClientId)
+	params.Set("client_secret", config.Tidal.ClientSecret)
+	params.Set("device_code", deviceCode)
+	params.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
+	params.Set("scope", scopes)
+	resp, err := http.PostForm(tokenURL, params)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	token := &tokenResponse{}
+	err = json.NewDecoder(resp.Body).Decode(token)
+	if err != nil {
+		return nil, err
+	}
+	if token.Error == "authorization_pending" {
+		return nil, nil
+	}
+	if token.Error == "slow_down" {
+		return nil, errSlowDown
+	}
+	if token.Error != "" {
+		return nil, errors.New(token.Error)
+	}
+	return token, nil
+}
+
+func saveToken(token *tokenResponse) error {
+	stateObj, err := state.GetState()
+	if err != nil {
+		return err
+	}
+	stateObj.Tidal.AccessToken = token.AccessToken
+	stateObj.Tidal.RefreshToken = token.RefreshToken
+	stateObj.Tidal.TokenType = token.TokenType
+	stateObj.Tidal.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return state.SaveState(stateObj)
+}
@@ -0,0 +1,426 @@
+package tidal
+
+import (
+	"api/internal/configuration"
+	"api/internal/provider"
+	"api/internal/state"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const apiURL = "https://openapi.tidal.com/v2"
+
+// maxItemsPerRequest is the amount of tracks Tidal accepts when adding them to a playlist
+const maxItemsPerRequest = 20
+
+var errNotFound = errors.New("not found on Tidal")
+
+type Track struct {
+	Id         string
+	Title      string
+	ISRC       string
+	DurationMs int
+	Explicit   bool
+	Artists    []string
+}
+
+type Playlist struct {
+	Id          string
+	Name        string
+	Description string
+}
+
+// resource is a resource of the JSON:API responses of Tidal
+type resource struct {
+	Id            string          `json:"id"`
+	Type          string          `json:"type"`
+	Attributes    json.RawMessage `json:"attributes,omitempty"`
+	Relationships map[string]struct {
+		Data []resource `json:"data"`
+	} `json:"relationships,omitempty"`
+	// Meta identifies the entry of a track in a playlist, which is needed to remove it
+	Meta *struct {
+		ItemId string `json:"itemId"`
+	} `json:"meta,omitempty"`
+}
+
+type document struct {
+	Data     json.RawMessage `json:"data"`
+	Included []resource      `json:"included"`
+	Links    struct {
+		Next string `json:"next"`
+	} `json:"links"`
+}
+
+// GetTracksByISRC returns the tracks of every release of the recording
+func GetTracksByISRC(isrc string) ([]Track, error) {
+	params := url.Values{}
+	params.Set("filter[isrc]", isrc)
+	return getTracks(params)
+}
+
+func SearchTracks(query string) ([]Track, error) {
+	params := url.Values{}
+	doc, err := request("GET", "/searchResults/"+url.PathEscape(query)+"/relationships/tracks", params, nil)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]resource, 0)
+	err = json.Unmarshal(doc.Data, &resources)
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 {
+		return make([]Track, 0), nil
+	}
+
+	// The search results only contain the ids, the artists are requested with the tracks
+	trackIds := make([]string, 0)
+	for _, trackResource := range resources {
+		trackIds = append(trackIds, trackResource.Id)
+	}
+	params = url.Values{}
+	params.Set("filter[id]", strings.Join(trackIds, ","))
+	return getTracks(params)
+}
+
+func getTracks(params url.Values) ([]Track, error) {
+	params.Set("include", "artists")
+	doc, err := request("GET", "/tracks", params, nil)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]resource, 0)
+	err = json.Unmarshal(doc.Data, &resources)
+	if err != nil {
+		return nil, err
+	}
+
+	artistNames := make(map[string]string)
+	for _, included := range doc.Included {
+		if included.Type == "artists" {
+			attributes := struct {
+				Name string `json:"name"`
+			}{}
+			_ = json.Unmarshal(included.Attributes, &attributes)
+			artistNames[included.Id] = attributes.Name
+		}
+	}
+
+	tracks := make([]Track, 0)
+	for _, trackResource := range resources {
+		attributes := struct {
+			Title    string `json:"title"`
+			ISRC     string `json:"isrc"`
+			Duration string `json:"duration"`
+			Explicit bool   `json:"explicit"`
+		}{}
+		err = json.Unmarshal(trackResource.Attributes, &attributes)
+		if err != nil {
+			return nil, err
+		}
+
+		track := Track{
+			Id:         trackResource.Id,
+			Title:      attributes.Title,
+			ISRC:       attributes.ISRC,
+			DurationMs: provider.ParseISODuration(attributes.Duration),
+			Explicit:   attributes.Explicit,
+			Artists:    make([]string, 0),
+		}
+		for _, artist := range trackResource.Relationships["artists"].Data {
+			track.Artists = append(track.Artists, artistNames[artist.Id])
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks, nil
+}
+
+func GetPlaylist(playlistId string) (*Playlist, error) {
+	doc, err := request("GET", "/playlists/"+playlistId, url.Values{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	playlistResource := resource{}
+	err = json.Unmarshal(doc.Data, &playlistResource)
+	if err != nil {
+		return nil, err
+	}
+	return readPlaylist(playlistResource)
+}
+
+func GetPlaylistTrackIds(playlistId string) ([]string, error) {
+	items, err := getPlaylistItems(playlistId)
+	if err != nil {
+		return nil, err
+	}
+
+	trackIds := make([]string, 0)
+	for _, item := range items {
+		trackIds = append(trackIds, item.Id)
+	}
+	return trackIds, nil
+}
+
+// getPlaylistItems returns the tracks of the playlist with the ids of their entries
+func getPlaylistItems(playlistId string) ([]resource, error) {
+	tracks := make([]resource, 0)
+	path := "/playlists/" + playlistId + "/relationships/items"
+	params := url.Values{}
+	for path != "" {
+		doc, err := request("GET", path, params, nil)
+		if err != nil {
+			return nil, err
+		}
+		items := make([]resource, 0)
+		err = json.Unmarshal(doc.Data, &items)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if item.Type == "tracks" {
+				tracks = append(tracks, item)
+			}
+		}
+
+		// The next page is a path with its own query, which already contains the cursor
+		path, params, err = getNextPage(doc.Links.Next)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tracks, nil
+}
+
+func CreatePlaylist(name string, description string) (*Playlist, error) {
+	body := map[string]interface{}{
+		"data": map[string]interface{}{
+			"type": "playlists",
+			"attributes": map[string]string{
+				"name":        name,
+				"description": description,
+				"accessType":  "UNLISTED",
+			},
+		},
+	}
+	doc, err := request("POST", "/playlists", url.Values{}, body)
+	if err != nil {
+		return nil, err
+	}
+	playlistResource := resource{}
+	err = json.Unmarshal(doc.Data, &playlistResource)
+	if err != nil {
+		return nil, err
+	}
+	return readPlaylist(playlistResource)
+}
+
+func AddTracksToPlaylist(playlistId string, trackIds []string) error {
+	for start := 0; start < len(trackIds); start += maxItemsPerRequest {
+		end := start + maxItemsPerRequest
+		if end > len(trackIds) {
+			end = len(trackIds)
+		}
+
+		items := make([]resource, 0)
+		for _, trackId := range trackIds[start:end] {
+			items = append(items, resource{Id: trackId, Type: "tracks"})
+		}
+		_, err := request("POST", "/playlists/"+playlistId+"/relationships/items", url.Values{},
+			map[string]interface{}{"data": items})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveTracksFromPlaylist removes every entry of the tracks from the playlist
+func RemoveTracksFromPlaylist(playlistId string, trackIds []string) error {
+	items, err := getPlaylistItems(playlistId)
+	if err != nil {
+		return err
+	}
+
+	remove := make(map[string]bool)
+	for _, trackId := range trackIds {
+		remove[trackId] = true
+	}
+	entries := make([]resource, 0)
+	for _, item := range items {
+		if remove[item.Id] && item.Meta != nil {
+			entries = append(entries, resource{Id: item.Id, Type: item.Type, Meta: item.Meta})
+		}
+	}
+
+	for start := 0; start < len(entries); start += maxItemsPerRequest {
+		end := start + maxItemsPerRequest
+		if end > len(entries) {
+			end = len(entries)
+		}
+		_, err = request("DELETE", "/playlists/"+playlistId+"/relationships/items", url.Values{},
+			map[string]interface{}{"data": entries[start:end]})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func DeletePlaylist(playlistId string) error {
+	_, err := request("DELETE", "/playlists/"+playlistId, url.Values{}, nil)
+	return err
+}
+
+func readPlaylist(playlistResource resource) (*Playlist, error) {
+	attributes := struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}{}
+	err := json.Unmarshal(playlistResource.Attributes, &attributes)
+	if err != nil {
+		return nil, err
+	}
+	return &Playlist{Id: playlistResource.Id, Name: attributes.Name, Description: attributes.Description}, nil
+}
+
+func getNextPage(next string) (string, url.Values, error) {
+	if next == "" {
+		return "", nil, nil
+	}
+	nextURL, err := url.Parse(next)
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.TrimPrefix(nextURL.Path, "/v2"), nextURL.Query(), nil
+}
+
+func getOAuthConfig() *oauth2.Config {
+	config, _ := configuration.GetConfiguration()
+	return &oauth2.Config{
+		ClientID:     config.Tidal.ClientId,
+		ClientSecret: config.Tidal.ClientSecret,
+		Endpoint:     oauth2.Endpoint{TokenURL: tokenURL, AuthStyle: oauth2.AuthStyleInParams},
+		Scopes:       strings.Split(scopes, " "),
+	}
+}
+
+func request(method string, path string, params url.Values, body interface{}) (*document, error) {
+	config, err := configuration.GetConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	stateObj, err := state.GetState()
+	if err != nil {
+		return nil, err
+	}
+	if stateObj.Tidal.RefreshToken == "" {
+		return nil, errors.New("not logged in to Tidal")
+	}
+
+	countryCode := config.Tidal.CountryCode
+	if countryCode == "" {
+		countryCode = "US"
+	}
+	params.Set("countryCode", countryCode)
+
+	var reader io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(content)
+	}
+	req, err := http.NewRequest(method, apiURL+path+"?"+params.Encode(), reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.api+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/vnd.api+json")
+	}
+
+	token := oauth2.Token{
+		AccessToken:  stateObj.Tidal.AccessToken,
+		RefreshToken: stateObj.Tidal.RefreshToken,
+		TokenType:    stateObj.Tidal.TokenType,
+		Expiry:       stateObj.Tidal.Expiry,
+	}
+	tokenSource := &savingTokenSource{
+		source: getOAuthConfig().TokenSource(context.TODO(), &token),
+		token:  token,
+	}
+	resp, err := oauth2.NewClient(context.TODO(), tokenSource).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode == 404 {
+		return nil, errNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, errors.New("Tidal responded with status " + resp.Status)
+	}
+
+	doc := &document{}
+	if resp.StatusCode == 204 {
+		return doc, nil
+	}
+	err = json.NewDecoder(resp.Body).Decode(doc)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// savingTokenSource saves the token to the state whenever it was refreshed, so the login survives a restart even when
+// Tidal hands out a new refresh token
+type savingTokenSource struct {
+	source oauth2.TokenSource
+	token  oauth2.Token
+}
+
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token.AccessToken == s.token.AccessToken && token.RefreshToken == s.token.RefreshToken {
+		return token, nil
+	}
+
+	s.token = *token
+	stateObj, err := state.GetState()
+	if err != nil {
+		return nil, err
+	}
+	stateObj.Tidal.AccessToken = token.AccessToken
+	stateObj.Tidal.RefreshToken = token.RefreshToken
+	stateObj.Tidal.TokenType = token.TokenType
+	stateObj.Tidal.Expiry = token.Expiry
+	err = state.SaveState(stateObj)
+	if err != nil {
+		getLogger().Warn("could not save the refreshed Tidal token: ", err.Error())
+	}
+	return token, nil
+}
+
+func getLogger() *zap.SugaredLogger {
+	logger, _ := zap.NewDevelopment()
+	sugar := logger.Sugar()
+	defer func(logger *zap.Logger) {
+		_ = logger.Sync()
+	}(logger)
+	return sugar
+}
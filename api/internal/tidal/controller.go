@@ -0,0 +1,17 @@
+package tidal
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func StartAuthEndpoint(c *gin.Context) {
+	deviceAuth, err := StartDeviceAuth()
+	if err != nil {
+		c.JSON(400, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, deviceAuth)
+}
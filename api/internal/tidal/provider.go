@@ -0,0 +1,114 @@
+package tidal
+
+import (
+	"api/internal/provider"
+	"errors"
+	"strings"
+)
+
+// Provider makes Tidal available as a destination. The ISRC filter of the catalog can return several releases of a
+// recording, so its candidates are compared like the search results are.
+type Provider struct{}
+
+func toTrack(track Track) provider.Track {
+	return provider.Track{
+		Id:         track.Id,
+		Name:       track.Title,
+		Artists:    track.Artists,
+		ISRC:       track.ISRC,
+		DurationMs: track.DurationMs,
+		Explicit:   track.Explicit,
+	}
+}
+
+func (Provider) FindTracks(tracks []provider.Track) ([]provider.MatchResult, error) {
+	results := make([]provider.MatchResult, 0)
+	for _, track := range tracks {
+		trackId, err := findTrack(track)
+		results = append(results, provider.MatchResult{TrackId: trackId, Err: err})
+	}
+	return results, nil
+}
+
+func findTrack(track provider.Track) (string, error) {
+	logger := getLogger()
+	candidates := make([]Track, 0)
+	if track.ISRC != "" {
+		isrcTracks, err := GetTracksByISRC(track.ISRC)
+		if err != nil {
+			return "", err
+		}
+		candidates = append(candidates, isrcTracks...)
+	}
+	if len(candidates) == 0 {
+		searchTracks, err := SearchTracks(strings.TrimSpace(track.ArtistNames() + " " + provider.NormalizeTitle(track.Name)))
+		if err != nil {
+			return "", err
+		}
+		candidates = append(candidates, searchTracks...)
+	}
+
+	for _, candidate := range candidates {
+		if provider.IsSameTrack(track, toTrack(candidate)) {
+			logger.Debug("found track on Tidal: ", candidate.Title)
+			return candidate.Id, nil
+		}
+	}
+	return "", nil
+}
+
+// GetSyncedPlaylist checks the playlist that was created before, a playlist that was removed in Tidal is created
+// again
+func (Provider) GetSyncedPlaylist(_ string, playlistId string) (*provider.Playlist, error) {
+	if playlistId == "" {
+		return nil, nil
+	}
+
+	playlist, err := GetPlaylist(playlistId)
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	item := toPlaylist(*playlist)
+	return &item, nil
+}
+
+func (p Provider) CreatePlaylist(name string, sourcePlaylistId string) (*provider.Playlist, error) {
+	return p.CreateNamedPlaylist(name, name+". Synced with spync. (ID: "+sourcePlaylistId+")")
+}
+
+func (Provider) CreateNamedPlaylist(name string, description string) (*provider.Playlist, error) {
+	playlist, err := CreatePlaylist(name, description)
+	if err != nil {
+		return nil, err
+	}
+
+	item := toPlaylist(*playlist)
+	return &item, nil
+}
+
+func (Provider) GetPlaylistTrackIds(playlistId string) ([]string, error) {
+	return GetPlaylistTrackIds(playlistId)
+}
+
+func (Provider) AddTracks(playlistId string, trackIds []string) error {
+	return AddTracksToPlaylist(playlistId, trackIds)
+}
+
+func (Provider) RemoveTracks(playlistId string, trackIds []string) error {
+	return RemoveTracksFromPlaylist(playlistId, trackIds)
+}
+
+func (Provider) DeletePlaylist(playlistId string) error {
+	return DeletePlaylist(playlistId)
+}
+
+func toPlaylist(playlist Playlist) provider.Playlist {
+	return provider.Playlist{
+		Id:          playlist.Id,
+		Name:        playlist.Name,
+		Description: playlist.Description,
+	}
+}
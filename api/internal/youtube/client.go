@@ -0,0 +1,401 @@
+package youtube
+
+import (
+	"api/internal/configuration"
+	"api/internal/provider"
+	"api/internal/state"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"html"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const apiURL = "https://www.googleapis.com/youtube/v3/"
+
+// musicCategoryId is the video category of music on YouTube
+const musicCategoryId = "10"
+
+type Video struct {
+	Id           string
+	Title        string
+	ChannelTitle string
+	DurationMs   int
+}
+
+type Playlist struct {
+	Id          string
+	Title       string
+	Description string
+}
+
+type snippet struct {
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	ChannelTitle string `json:"channelTitle"`
+}
+
+func getOAuthConfig() *oauth2.Config {
+	config, _ := configuration.GetConfiguration()
+	return &oauth2.Config{
+		ClientID:     config.YouTube.ClientId,
+		ClientSecret: config.YouTube.ClientSecret,
+		RedirectURL:  config.YouTube.RedirectUrl,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  "https://accounts.google.com/o/oauth2/auth",
+			TokenURL: "https://oauth2.googleapis.com/token",
+		},
+		Scopes: []string{"https://www.googleapis.com/auth/youtube"},
+	}
+}
+
+func GetAuthUrl() string {
+	// Google only returns a refresh token for offline access
+	return getOAuthConfig().AuthCodeURL(fmt.Sprint("spync-", rand.Int()), oauth2.AccessTypeOffline,
+		oauth2.ApprovalForce)
+}
+
+func SaveAuth(r *http.Request) error {
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		return errors.New("YouTube did not return a code: " + r.URL.Query().Get("error"))
+	}
+	token, err := getOAuthConfig().Exchange(r.Context(), code)
+	if err != nil {
+		return err
+	}
+
+	stateObj, _ := state.GetState()
+	stateObj.YouTube.AccessToken = token.AccessToken
+	stateObj.YouTube.RefreshToken = token.RefreshToken
+	stateObj.YouTube.Expiry = token.Expiry
+	stateObj.YouTube.TokenType = token.TokenType
+	return state.SaveState(stateObj)
+}
+
+// SearchVideos returns the music videos for the query with their durations. Every search costs a large part of
+// the daily quota of the YouTube Data API.
+func SearchVideos(query string) ([]Video, error) {
+	params := url.Values{}
+	params.Set("part", "snippet")
+	params.Set("type", "video")
+	params.Set("videoCategoryId", musicCategoryId)
+	params.Set("maxResults", "10")
+	params.Set("q", query)
+	resp := struct {
+		Items []struct {
+			Id struct {
+				VideoId string `json:"videoId"`
+			} `json:"id"`
+			Snippet snippet `json:"snippet"`
+		} `json:"items"`
+	}{}
+	err := request("GET", "search", params, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	videos := make([]Video, 0)
+	videoIds := make([]string, 0)
+	for _, item := range resp.Items {
+		videos = append(videos, Video{
+			Id:           item.Id.VideoId,
+			Title:        html.UnescapeString(item.Snippet.Title),
+			ChannelTitle: html.UnescapeString(item.Snippet.ChannelTitle),
+		})
+		videoIds = append(videoIds, item.Id.VideoId)
+	}
+	if len(videoIds) == 0 {
+		return videos, nil
+	}
+
+	// Search results have no duration
+	params = url.Values{}
+	params.Set("part", "contentDetails")
+	params.Set("id", strings.Join(videoIds, ","))
+	details := struct {
+		Items []struct {
+			Id             string `json:"id"`
+			ContentDetails struct {
+				Duration string `json:"duration"`
+			} `json:"contentDetails"`
+		} `json:"items"`
+	}{}
+	err = request("GET", "videos", params, nil, &details)
+	if err != nil {
+		return nil, err
+	}
+	durations := make(map[string]int)
+	for _, item := range details.Items {
+		durations[item.Id] = provider.ParseISODuration(item.ContentDetails.Duration)
+	}
+	for i := range videos {
+		videos[i].DurationMs = durations[videos[i].Id]
+	}
+	return videos, nil
+}
+
+func GetPlaylists() ([]Playlist, error) {
+	playlists := make([]Playlist, 0)
+	pageToken := ""
+	for {
+		params := url.Values{}
+		params.Set("part", "snippet")
+		params.Set("mine", "true")
+		params.Set("maxResults", "50")
+		params.Set("pageToken", pageToken)
+		resp := struct {
+			Items []struct {
+				Id      string  `json:"id"`
+				Snippet snippet `json:"snippet"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}{}
+		err := request("GET", "playlists", params, nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range resp.Items {
+			playlists = append(playlists, Playlist{
+				Id:          item.Id,
+				Title:       item.Snippet.Title,
+				Description: item.Snippet.Description,
+			})
+		}
+		if resp.NextPageToken == "" {
+			return playlists, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+func GetPlaylistVideoIds(playlistId string) ([]string, error) {
+	items, err := getPlaylistItems(playlistId)
+	if err != nil {
+		return nil, err
+	}
+
+	videoIds := make([]string, 0)
+	for _, item := range items {
+		videoIds = append(videoIds, item.videoId)
+	}
+	return videoIds, nil
+}
+
+// playlistItem is the entry of a video in a playlist
+type playlistItem struct {
+	id      string
+	videoId string
+}
+
+func getPlaylistItems(playlistId string) ([]playlistItem, error) {
+	items := make([]playlistItem, 0)
+	pageToken := ""
+	for {
+		params := url.Values{}
+		params.Set("part", "id,contentDetails")
+		params.Set("playlistId", playlistId)
+		params.Set("maxResults", "50")
+		params.Set("pageToken", pageToken)
+		resp := struct {
+			Items []struct {
+				Id             string `json:"id"`
+				ContentDetails struct {
+					VideoId string `json:"videoId"`
+				} `json:"contentDetails"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}{}
+		err := request("GET", "playlistItems", params, nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range resp.Items {
+			items = append(items, playlistItem{id: item.Id, videoId: item.ContentDetails.VideoId})
+		}
+		if resp.NextPageToken == "" {
+			return items, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+func CreatePlaylist(name string, description string) (*Playlist, error) {
+	params := url.Values{}
+	params.Set("part", "snippet,status")
+	body := map[string]interface{}{
+		"snippet": map[string]string{
+			"title":       name,
+			"description": description,
+		},
+		"status": map[string]string{
+			"privacyStatus": "private",
+		},
+	}
+	resp := struct {
+		Id string `json:"id"`
+	}{}
+	err := request("POST", "playlists", params, body, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &Playlist{Id: resp.Id, Title: name, Description: description}, nil
+}
+
+// AddVideosToPlaylist adds the videos one by one, YouTube has no batch insert for playlist items
+func AddVideosToPlaylist(playlistId string, videoIds []string) error {
+	params := url.Values{}
+	params.Set("part", "snippet")
+	for _, videoId := range videoIds {
+		body := map[string]interface{}{
+			"snippet": map[string]interface{}{
+				"playlistId": playlistId,
+				"resourceId": map[string]string{
+					"kind":    "youtube#video",
+					"videoId": videoId,
+				},
+			},
+		}
+		err := request("POST", "playlistItems", params, body, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveVideosFromPlaylist removes every entry of the videos one by one, like they are added
+func RemoveVideosFromPlaylist(playlistId string, videoIds []string) error {
+	items, err := getPlaylistItems(playlistId)
+	if err != nil {
+		return err
+	}
+
+	remove := make(map[string]bool)
+	for _, videoId := range videoIds {
+		remove[videoId] = true
+	}
+	for _, item := range items {
+		if !remove[item.videoId] {
+			continue
+		}
+		params := url.Values{}
+		params.Set("id", item.id)
+		err = request("DELETE", "playlistItems", params, nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func DeletePlaylist(playlistId string) error {
+	params := url.Values{}
+	params.Set("id", playlistId)
+	return request("DELETE", "playlists", params, nil, nil)
+}
+
+func getYouTubeClient() *http.Client {
+	stateObj, _ := state.GetState()
+	token := oauth2.Token{
+		AccessToken:  stateObj.YouTube.AccessToken,
+		RefreshToken: stateObj.YouTube.RefreshToken,
+		TokenType:    stateObj.YouTube.TokenType,
+		Expiry:       stateObj.YouTube.Expiry,
+	}
+	tokenSource := &savingTokenSource{
+		source: getOAuthConfig().TokenSource(context.TODO(), &token),
+		token:  token,
+	}
+	return oauth2.NewClient(context.TODO(), tokenSource)
+}
+
+// savingTokenSource saves the token to the state whenever it was refreshed, Google access tokens only last an hour
+type savingTokenSource struct {
+	source oauth2.TokenSource
+	token  oauth2.Token
+}
+
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token.AccessToken == s.token.AccessToken && token.RefreshToken == s.token.RefreshToken {
+		return token, nil
+	}
+
+	s.token = *token
+	stateObj, err := state.GetState()
+	if err != nil {
+		return nil, err
+	}
+	stateObj.YouTube.AccessToken = token.AccessToken
+	stateObj.YouTube.RefreshToken = token.RefreshToken
+	stateObj.YouTube.TokenType = token.TokenType
+	stateObj.YouTube.Expiry = token.Expiry
+	err = state.SaveState(stateObj)
+	if err != nil {
+		getLogger().Warn("could not save the refreshed YouTube token: ", err.Error())
+	}
+	return token, nil
+}
+
+func request(method string, path string, params url.Values, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(content)
+	}
+	req, err := http.NewRequest(method, apiURL+path+"?"+params.Encode(), reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := getYouTubeClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		errResp := struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}{}
+		_ = json.NewDecoder(resp.Body).Decode(&errResp)
+		return errors.New("YouTube responded with status " + resp.Status + ": " + errResp.Error.Message)
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func getLogger() *zap.SugaredLogger {
+	logger, _ := zap.NewDevelopment()
+	sugar := logger.Sugar()
+	defer func(logger *zap.Logger) {
+		_ = logger.Sync()
+	}(logger)
+	return sugar
+}
@@ -0,0 +1,23 @@
+package youtube
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func GetAuthURLEndpoint(c *gin.Context) {
+	c.Redirect(301, GetAuthUrl())
+}
+
+func SaveAuthEndpoint(c *gin.Context) {
+	err := SaveAuth(c.Request)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Logged in to YouTube",
+	})
+}
@@ -0,0 +1,139 @@
+package youtube
+
+import (
+	"api/internal/provider"
+	"regexp"
+	"strings"
+)
+
+// topicSuffix marks the channels YouTube generates for artists, their videos are the official song uploads
+const topicSuffix = " - Topic"
+
+// videoDecorationRegex matches the additions to video titles that are not part of the song title
+var videoDecorationRegex = regexp.MustCompile(`(?i)\s*[(\[][^)\]]*\b(?:official|video|audio|lyrics?|visuali[sz]er|hd|4k)\b[^)\]]*[)\]]`)
+
+// Provider makes YouTube Music available as a destination. Songs are searched by artist and title, uploads of
+// topic channels are preferred over other videos.
+type Provider struct{}
+
+func (Provider) FindTracks(tracks []provider.Track) ([]provider.MatchResult, error) {
+	results := make([]provider.MatchResult, 0)
+	for _, track := range tracks {
+		videoId, err := findVideo(track)
+		results = append(results, provider.MatchResult{TrackId: videoId, Err: err})
+	}
+	return results, nil
+}
+
+func findVideo(track provider.Track) (string, error) {
+	logger := getLogger()
+	artist := ""
+	if len(track.Artists) > 0 {
+		artist = track.Artists[0]
+	}
+	videos, err := SearchVideos(strings.TrimSpace(artist + " " + provider.NormalizeTitle(track.Name)))
+	if err != nil {
+		return "", err
+	}
+
+	// Topic uploads are named after the song, and their channel after the artist
+	for _, video := range videos {
+		if !strings.HasSuffix(video.ChannelTitle, topicSuffix) {
+			continue
+		}
+		candidate := provider.Track{
+			Name:       video.Title,
+			Artists:    []string{strings.TrimSuffix(video.ChannelTitle, topicSuffix)},
+			DurationMs: video.DurationMs,
+		}
+		if provider.IsSameTrack(track, candidate) {
+			logger.Debug("found song on YouTube: ", video.Title)
+			return video.Id, nil
+		}
+	}
+
+	// Other videos usually have titles like "Artist - Title (Official Video)"
+	for _, video := range videos {
+		artist, title, ok := parseVideoTitle(video.Title)
+		if !ok {
+			continue
+		}
+		candidate := provider.Track{
+			Name:       title,
+			Artists:    []string{artist},
+			DurationMs: video.DurationMs,
+		}
+		if provider.IsSameTrack(track, candidate) {
+			logger.Debug("found video on YouTube: ", video.Title)
+			return video.Id, nil
+		}
+	}
+	return "", nil
+}
+
+func (Provider) GetSyncedPlaylist(sourcePlaylistId string, _ string) (*provider.Playlist, error) {
+	playlists, err := GetPlaylists()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, playlist := range playlists {
+		if strings.Contains(playlist.Description, "(ID: "+sourcePlaylistId+")") {
+			item := toPlaylist(playlist)
+			return &item, nil
+		}
+	}
+	return nil, nil
+}
+
+func (p Provider) CreatePlaylist(name string, sourcePlaylistId string) (*provider.Playlist, error) {
+	return p.CreateNamedPlaylist(name, name+". Synced with spync. (ID: "+sourcePlaylistId+")")
+}
+
+func (Provider) CreateNamedPlaylist(name string, description string) (*provider.Playlist, error) {
+	playlist, err := CreatePlaylist(name, description)
+	if err != nil {
+		return nil, err
+	}
+
+	item := toPlaylist(*playlist)
+	return &item, nil
+}
+
+func (Provider) GetPlaylistTrackIds(playlistId string) ([]string, error) {
+	return GetPlaylistVideoIds(playlistId)
+}
+
+func (Provider) AddTracks(playlistId string, trackIds []string) error {
+	return AddVideosToPlaylist(playlistId, trackIds)
+}
+
+func (Provider) RemoveTracks(playlistId string, trackIds []string) error {
+	return RemoveVideosFromPlaylist(playlistId, trackIds)
+}
+
+func (Provider) DeletePlaylist(playlistId string) error {
+	return DeletePlaylist(playlistId)
+}
+
+func toPlaylist(playlist Playlist) provider.Playlist {
+	return provider.Playlist{
+		Id:          playlist.Id,
+		Name:        playlist.Title,
+		Description: playlist.Description,
+	}
+}
+
+// parseVideoTitle splits a title like "Artist - Title (Official Video)" into the artist and the title of the song
+func parseVideoTitle(videoTitle string) (string, string, bool) {
+	artist, title, found := strings.Cut(videoTitle, " - ")
+	if !found {
+		return "", "", false
+	}
+	artist = strings.TrimSpace(artist)
+	title = strings.TrimSpace(videoDecorationRegex.ReplaceAllString(title, ""))
+	if artist == "" || title == "" {
+		return "", "", false
+	}
+	return artist, title, true
+}